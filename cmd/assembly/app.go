@@ -4,38 +4,83 @@ import (
 	"fmt"
 
 	"venues/cmd/routes"
+	"venues/cmd/settings"
 	"venues/cmd/storages"
 	"venues/pkg/healthcheckers"
+	"venues/pkg/httperrors"
+	"venues/pkg/logging"
+	"venues/pkg/metrics"
+	"venues/pkg/tracing"
 	"venues/pkg/validator"
 
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
-	"time"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
 
+// metricsPath is where Prometheus scrapes the app
+const metricsPath = "/metrics"
+
+// defaultCitiesFile is the bundled list of known cities
+const defaultCitiesFile = "$GOPATH/src/venues/data/cities.csv"
+
 type App struct {
 	*echo.Echo
+
+	purgeJob *PurgeJob
+	// shutdown is closed when requests still in progress have to stop
+	shutdown chan struct{}
 }
 
 func (app *App) setMiddleware() {
-	app.Use(middleware.Logger())
+	app.Use(cancelOnShutdown(app.shutdown))
+	app.Use(logging.Middleware(logging.Config{Skipper: isProbe}))
+	app.Use(metrics.NewHTTPMetrics(metrics.Default()).Middleware(nil))
+	app.Use(tracing.Middleware(tracing.Config{Skipper: isProbe}))
+	// errors are rendered inside the middleware above, so it sees the status they are rendered with
+	app.Use(httperrors.Middleware())
 	app.Use(middleware.Recover())
+
+	ipRateLimitMiddleware, err := newIPRateLimitMiddleware()
+	if err != nil {
+		app.Logger.Fatal(err)
+	}
+	app.Use(ipRateLimitMiddleware)
+
+	authMiddleware, err := newAuthMiddleware()
+	if err != nil {
+		app.Logger.Fatal(err)
+	}
+	app.Use(authMiddleware)
+
+	rateLimitMiddleware, err := newRateLimitMiddleware()
+	if err != nil {
+		app.Logger.Fatal(err)
+	}
+	app.Use(rateLimitMiddleware)
 }
 
 func (app *App) setRoutes() {
 	mongoHealthChecker := &healthcheckers.CheckService{
 		ServiceName: "Mongo",
-		Action:      storages.GetStorage().Session.Ping,
+		Action:      storages.Ping,
 	}
-	healthCkecker := HealthCheck{[]healthcheckers.Checker{mongoHealthChecker}}
-	app.GET("/", healthCkecker.Check)
+	healthCheck := NewHealthCheck(mongoHealthChecker)
+	app.GET(livenessPath, healthCheck.Live)
+	app.GET(readinessPath, healthCheck.Ready)
+	// older probes check the root
+	app.GET("/", healthCheck.Ready)
+	app.GET(metricsPath, metrics.Handler(metrics.Default()))
 
 	restaurantGroup := app.Group("/restaurants")
 	routes.BuildRestaurantGroup(restaurantGroup)
+
+	auditGroup := app.Group("/audit")
+	routes.BuildAuditGroup(auditGroup)
 }
 
 func (app *App) init() {
@@ -46,30 +91,61 @@ func (app *App) init() {
 
 func (app *App) Run(port string) {
 	startPort := fmt.Sprintf(":%s", port)
-	go app.Logger.Fatal(app.Start(startPort))
+	go func() {
+		if err := app.Start(startPort); err != nil && err != http.ErrServerClosed {
+			app.Logger.Fatal(err)
+		}
+	}()
+
+	if app.purgeJob != nil {
+		app.purgeJob.Start()
+	}
 
-	quit := make(chan os.Signal)
+	// signal.Notify doesn't block sending, a signal would be lost without a buffer
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	// do not forget about bubble ordering of defers
-	// close sessions before
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
-	defer storages.GetStorage().Session.Close()
-	if err := app.Shutdown(ctx); err != nil {
-		app.Logger.Fatal(err)
+	err := app.Shutdown(ctx)
+
+	// requests still in progress after the timeout and the purge stop their storage operations,
+	// sessions are closed after them
+	close(app.shutdown)
+	if app.purgeJob != nil {
+		app.purgeJob.Stop()
 	}
+	storages.Close()
 
+	if err != nil {
+		app.Logger.Fatal(err)
+	}
 }
 
 func NewApp() *App {
-	app := &App{echo.New()}
+	app := &App{Echo: echo.New(), shutdown: make(chan struct{})}
+	// echo and its middleware log through the default logger as well
+	app.Logger = logging.NewEchoLogger(logging.Default())
+	if err := configureLogger(); err != nil {
+		app.Logger.Fatal(err)
+	}
+	if err := configureTracer(); err != nil {
+		app.Logger.Fatal(err)
+	}
+
+	cities, err := validator.NewFileCityResolver(settings.GetSetting("CITIES_FILE", os.ExpandEnv(defaultCitiesFile)))
+	if err != nil {
+		app.Logger.Fatal(err)
+	}
 
 	// setup validator that will be used by echo.Context.Bind
-	app.Validator = validator.NewValidator()
+	app.Validator = validator.NewValidator(cities)
+	// every error is rendered as httperrors.Error json
+	app.HTTPErrorHandler = httperrors.Handler
 
 	app.init()
+	app.purgeJob = NewPurgeJob(logging.Default())
 
 	return app
 }
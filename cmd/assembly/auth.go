@@ -0,0 +1,52 @@
+package assembly
+
+import (
+	"io/ioutil"
+
+	"venues/cmd/repositories"
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+
+	"github.com/labstack/echo"
+)
+
+// newAuthMiddleware authenticates requests by API keys stored in mongo and by JWTs
+// signed with JWT_HS256_SECRET or with the private key of JWT_RS256_PUBLIC_KEY_FILE,
+// reads are public unless PUBLIC_READS=false, the health check and metrics are always public
+func newAuthMiddleware() (echo.MiddlewareFunc, error) {
+	authenticators := []auth.Authenticator{&auth.APIKeyAuthenticator{Store: repositories.NewAPIKeyRepo()}}
+
+	jwtAuthenticator := &auth.JWTAuthenticator{
+		HMACKey: []byte(settings.GetSetting("JWT_HS256_SECRET", "")),
+		Issuer:  settings.GetSetting("JWT_ISSUER", ""),
+	}
+	if file := settings.GetSetting("JWT_RS256_PUBLIC_KEY_FILE", ""); file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if jwtAuthenticator.RSAKey, err = auth.ParseRSAPublicKey(data); err != nil {
+			return nil, err
+		}
+	}
+	if len(jwtAuthenticator.HMACKey) > 0 || jwtAuthenticator.RSAKey != nil {
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	return auth.Middleware(auth.Config{
+		Skipper:        isProbe,
+		Authenticators: authenticators,
+		PublicReads:    settings.GetBoolSetting("PUBLIC_READS", true),
+	}), nil
+}
+
+// isProbe tells requests of health checkers and metrics scrapers
+func isProbe(context echo.Context) bool {
+	switch context.Path() {
+	case "/", livenessPath, readinessPath, metricsPath:
+		return true
+	}
+
+	return false
+}
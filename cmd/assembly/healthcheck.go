@@ -2,22 +2,64 @@ package assembly
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
+	"venues/cmd/settings"
 	"venues/pkg/healthcheckers"
 
 	"github.com/labstack/echo"
 )
 
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+const (
+	defaultHealthCheckTimeout = 2 * time.Second
+	defaultHealthCacheFor     = 5 * time.Second
+)
+
+// HealthCheck answers liveness probes without checking anything, the app is alive while it answers them,
+// readiness probes get the report of the checkers, which is 503 only when a critical checker is down
 type HealthCheck struct {
-	checkers []healthcheckers.Checker
+	runner *healthcheckers.Runner
 }
 
-func (h *HealthCheck) Check(c echo.Context) error {
-	for _, checker := range h.checkers {
-		if err := checker.Check(); err != nil {
-			return c.String(http.StatusServiceUnavailable, checker.Message())
+// NewHealthCheck runs every checker for HEALTH_CHECK_TIMEOUT at most and keeps the report for HEALTH_CACHE_FOR,
+// HEALTH_NON_CRITICAL lists names of checkers, like "Mongo", that only degrade the app
+func NewHealthCheck(checkers ...healthcheckers.Checker) *HealthCheck {
+	nonCritical := map[string]bool{}
+	for _, name := range strings.Split(settings.GetSetting("HEALTH_NON_CRITICAL", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			nonCritical[strings.ToLower(name)] = true
 		}
 	}
 
-	return c.String(http.StatusOK, "Ok")
+	checks := make([]healthcheckers.Check, len(checkers))
+	for i, checker := range checkers {
+		checks[i] = healthcheckers.Check{Checker: checker, NonCritical: nonCritical[strings.ToLower(checker.Message())]}
+	}
+
+	return &HealthCheck{runner: &healthcheckers.Runner{
+		Checks:   checks,
+		Timeout:  settings.GetDurationSetting("HEALTH_CHECK_TIMEOUT", defaultHealthCheckTimeout),
+		CacheFor: settings.GetDurationSetting("HEALTH_CACHE_FOR", defaultHealthCacheFor),
+	}}
+}
+
+func (h *HealthCheck) Live(c echo.Context) error {
+	report := &healthcheckers.Report{Status: healthcheckers.StatusUp, CheckedAt: time.Now(), Checks: []healthcheckers.Result{}}
+	return c.JSON(http.StatusOK, report)
+}
+
+// Ready is 200 for a degraded app, it still serves requests
+func (h *HealthCheck) Ready(c echo.Context) error {
+	report := h.runner.Run()
+	if report.Status == healthcheckers.StatusDown {
+		return c.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return c.JSON(http.StatusOK, report)
 }
@@ -4,10 +4,12 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"venues/pkg/healthcheckers"
 
 	"github.com/labstack/echo"
@@ -22,44 +24,85 @@ func (m *MockService) Check() error {
 	return args.Error(0)
 }
 func (m *MockService) Message() string {
-	return ""
+	return "Mongo"
 }
 
 type HealthCheckTestSuite struct {
 	suite.Suite
 
+	recorder    *httptest.ResponseRecorder
 	echoContext echo.Context
 	healthCheck *HealthCheck
 }
 
 func (suite *HealthCheckTestSuite) SetupTest() {
-	req := httptest.NewRequest(echo.GET, "/", nil)
-	rec := httptest.NewRecorder()
-	suite.echoContext = echo.New().NewContext(req, rec)
+	req := httptest.NewRequest(echo.GET, "/readyz", nil)
+	suite.recorder = httptest.NewRecorder()
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+}
+
+func (suite *HealthCheckTestSuite) newHealthCheck(mockService *MockService, nonCritical bool) {
+	check := healthcheckers.Check{Checker: mockService, NonCritical: nonCritical}
+	suite.healthCheck = &HealthCheck{&healthcheckers.Runner{Checks: []healthcheckers.Check{check}, Timeout: time.Second}}
+}
+
+func (suite *HealthCheckTestSuite) report() *healthcheckers.Report {
+	report := &healthcheckers.Report{}
+	suite.Assertions.Nil(json.Unmarshal(suite.recorder.Body.Bytes(), report))
+	return report
 }
 
 func (suite *HealthCheckTestSuite) TestSuccess() {
 	mockService := &MockService{}
-	suite.healthCheck = &HealthCheck{[]healthcheckers.Checker{mockService}}
+	suite.newHealthCheck(mockService, false)
 
 	mockService.On("Check").Return(nil)
 
-	suite.healthCheck.Check(suite.echoContext)
+	suite.healthCheck.Ready(suite.echoContext)
 
 	mockService.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	report := suite.report()
+	suite.Assertions.Equal(report.Status, healthcheckers.StatusUp)
+	suite.Assertions.Equal(report.Checks[0].Name, "Mongo")
 }
 
 func (suite *HealthCheckTestSuite) TestFail() {
 	mockService := &MockService{}
-	suite.healthCheck = &HealthCheck{[]healthcheckers.Checker{mockService}}
+	suite.newHealthCheck(mockService, false)
 
 	mockService.On("Check").Return(errors.New("mocked error"))
 
-	suite.healthCheck.Check(suite.echoContext)
+	suite.healthCheck.Ready(suite.echoContext)
 
 	mockService.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
+	report := suite.report()
+	suite.Assertions.Equal(report.Status, healthcheckers.StatusDown)
+	suite.Assertions.Equal(report.Checks[0].Error, "mocked error")
+}
+
+func (suite *HealthCheckTestSuite) TestDegraded() {
+	mockService := &MockService{}
+	suite.newHealthCheck(mockService, true)
+
+	mockService.On("Check").Return(errors.New("mocked error"))
+
+	suite.healthCheck.Ready(suite.echoContext)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(suite.report().Status, healthcheckers.StatusDegraded)
+}
+
+func (suite *HealthCheckTestSuite) TestLive() {
+	mockService := &MockService{}
+	suite.newHealthCheck(mockService, false)
+
+	suite.healthCheck.Live(suite.echoContext)
+
+	mockService.AssertNotCalled(suite.T(), "Check")
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(suite.report().Status, healthcheckers.StatusUp)
 }
 
 func TestHealthCheck(t *testing.T) {
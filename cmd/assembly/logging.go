@@ -0,0 +1,36 @@
+package assembly
+
+import (
+	"io"
+	"os"
+
+	"venues/cmd/settings"
+	"venues/pkg/logging"
+)
+
+// configureLogger sets the level of the default logger to LOG_LEVEL, "info" by default,
+// and its output to LOG_OUTPUT, which is "stdout", "stderr" or a file entries are appended to
+func configureLogger() error {
+	level, err := logging.ParseLevel(settings.GetSetting("LOG_LEVEL", logging.InfoLevel.String()))
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer
+	switch name := settings.GetSetting("LOG_OUTPUT", "stdout"); name {
+	case "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		if output, err = os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return err
+		}
+	}
+
+	logger := logging.Default()
+	logger.SetLevel(level)
+	logger.SetOutput(output)
+
+	return nil
+}
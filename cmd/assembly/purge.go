@@ -0,0 +1,101 @@
+package assembly
+
+import (
+	"context"
+	"time"
+
+	"venues/cmd/repositories"
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+	"venues/pkg/logging"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+type restaurantPurger interface {
+	Purge(context.Context, time.Time) ([]bson.ObjectId, error)
+}
+
+type reviewPurger interface {
+	Purge(context.Context, []bson.ObjectId) error
+}
+
+// purgePrincipal is the actor of purges in the audit log
+var purgePrincipal = &auth.Principal{Subject: "purge", Method: "system"}
+
+// PurgeJob permanently removes restaurants that are in the trash longer than MaxAge,
+// reviews of the restaurants are removed with them
+type PurgeJob struct {
+	Restaurants restaurantPurger
+	Reviews     reviewPurger
+	MaxAge      time.Duration
+	Interval    time.Duration
+	Logger      *logging.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Purge removes restaurants that were moved to the trash before now - MaxAge,
+// reviews of the restaurants purged before an error are removed as well
+func (job *PurgeJob) Purge(ctx context.Context, now time.Time) error {
+	ids, err := job.Restaurants.Purge(ctx, now.Add(-job.MaxAge))
+	if len(ids) == 0 {
+		return err
+	}
+
+	if reviewErr := job.Reviews.Purge(ctx, ids); err == nil {
+		err = reviewErr
+	}
+
+	return err
+}
+
+// Start purges the trash right away and then every Interval until the job is stopped
+func (job *PurgeJob) Start() {
+	ctx := logging.WithContext(auth.WithPrincipal(context.Background(), purgePrincipal), job.Logger)
+	ctx, cancel := context.WithCancel(ctx)
+	job.cancel = cancel
+	job.done = make(chan struct{})
+	ticker := time.NewTicker(job.Interval)
+
+	go func() {
+		defer close(job.done)
+		defer ticker.Stop()
+		for {
+			if err := job.Purge(ctx, time.Now()); err != nil && ctx.Err() == nil {
+				job.Logger.WithError(err).Error("Error purging the trash")
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop cancels the purge in progress and waits for it, so storages can be closed after it
+func (job *PurgeJob) Stop() {
+	job.cancel()
+	<-job.done
+}
+
+// NewPurgeJob is nil when PURGE_AFTER_DAYS is 0, restaurants are kept in the trash forever then,
+// purged restaurants are recorded in the audit log
+func NewPurgeJob(logger *logging.Logger) *PurgeJob {
+	days := settings.GetIntSetting("PURGE_AFTER_DAYS", 30)
+	if days <= 0 {
+		return nil
+	}
+
+	restaurants := repositories.NewAuditedRestaurantRepo(repositories.NewRestaurantRepo(), repositories.NewAuditRepo())
+	return &PurgeJob{
+		Restaurants: restaurants,
+		Reviews:     repositories.NewReviewRepo(restaurants),
+		MaxAge:      time.Duration(days) * 24 * time.Hour,
+		Interval:    time.Duration(settings.GetIntSetting("PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+		Logger:      logger,
+	}
+}
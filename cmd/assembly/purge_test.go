@@ -0,0 +1,116 @@
+package assembly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"venues/pkg/auth"
+	"venues/pkg/logging"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type MockRestaurantPurger struct {
+	mock.Mock
+
+	// ctx is the context of the last purge
+	ctx context.Context
+}
+
+func (m *MockRestaurantPurger) Purge(ctx context.Context, before time.Time) ([]bson.ObjectId, error) {
+	m.ctx = ctx
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bson.ObjectId), args.Error(1)
+}
+
+type MockReviewPurger struct {
+	mock.Mock
+}
+
+func (m *MockReviewPurger) Purge(ctx context.Context, ids []bson.ObjectId) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+type PurgeJobTestSuite struct {
+	suite.Suite
+
+	restaurants *MockRestaurantPurger
+	reviews     *MockReviewPurger
+	job         *PurgeJob
+	now         time.Time
+}
+
+func (suite *PurgeJobTestSuite) SetupTest() {
+	suite.restaurants = &MockRestaurantPurger{}
+	suite.reviews = &MockReviewPurger{}
+	suite.job = &PurgeJob{Restaurants: suite.restaurants, Reviews: suite.reviews, MaxAge: 30 * 24 * time.Hour}
+	suite.now = time.Date(2018, 3, 31, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *PurgeJobTestSuite) TestPurgeSuccess() {
+	ids := []bson.ObjectId{bson.NewObjectId()}
+	suite.restaurants.On("Purge", time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)).Return(ids, nil)
+	suite.reviews.On("Purge", ids).Return(nil)
+
+	err := suite.job.Purge(context.Background(), suite.now)
+
+	suite.restaurants.AssertExpectations(suite.T())
+	suite.reviews.AssertExpectations(suite.T())
+	suite.Assertions.Nil(err)
+}
+
+func (suite *PurgeJobTestSuite) TestPurgeNothing() {
+	suite.restaurants.On("Purge", mock.Anything).Return(nil, nil)
+
+	err := suite.job.Purge(context.Background(), suite.now)
+
+	suite.restaurants.AssertExpectations(suite.T())
+	suite.reviews.AssertNotCalled(suite.T(), "Purge", mock.Anything)
+	suite.Assertions.Nil(err)
+}
+
+func (suite *PurgeJobTestSuite) TestPurgeFail() {
+	suite.restaurants.On("Purge", mock.Anything).Return(nil, errors.New("mocked error"))
+
+	err := suite.job.Purge(context.Background(), suite.now)
+
+	suite.reviews.AssertNotCalled(suite.T(), "Purge", mock.Anything)
+	suite.Assertions.Error(err)
+}
+
+func (suite *PurgeJobTestSuite) TestPurgeFailAfterSome() {
+	ids := []bson.ObjectId{bson.NewObjectId()}
+	suite.restaurants.On("Purge", mock.Anything).Return(ids, errors.New("mocked error"))
+	suite.reviews.On("Purge", ids).Return(nil)
+
+	err := suite.job.Purge(context.Background(), suite.now)
+
+	// reviews of the restaurants purged before the error aren't left behind
+	suite.reviews.AssertExpectations(suite.T())
+	suite.Assertions.Error(err)
+}
+
+func (suite *PurgeJobTestSuite) TestStop() {
+	suite.restaurants.On("Purge", mock.Anything).Return(nil, nil)
+	suite.job.Interval = time.Hour
+	suite.job.Logger = logging.Default()
+
+	suite.job.Start()
+	suite.job.Stop()
+
+	suite.restaurants.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.restaurants.ctx.Err(), context.Canceled)
+	suite.Assertions.Equal(auth.PrincipalFromContext(suite.restaurants.ctx).ID(), "system:purge")
+}
+
+func TestPurgeJobTestSuite(t *testing.T) {
+	suite.Run(t, new(PurgeJobTestSuite))
+}
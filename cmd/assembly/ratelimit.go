@@ -0,0 +1,86 @@
+package assembly
+
+import (
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+	"venues/pkg/ratelimit"
+
+	"github.com/labstack/echo"
+)
+
+// defaultRateLimit is generous for clients and still stops a client listing whole collections in a loop
+const defaultRateLimit = "600/m"
+
+// defaultIPRateLimit lets a few clients share an address
+const defaultIPRateLimit = "1200/m"
+
+// newIPRateLimitMiddleware limits every address by RATE_LIMIT_IP. It runs before authentication,
+// so requests with wrong credentials are counted too and credentials can't be guessed faster than that.
+func newIPRateLimitMiddleware() (echo.MiddlewareFunc, error) {
+	limit, err := ratelimit.ParseLimit(settings.GetSetting("RATE_LIMIT_IP", defaultIPRateLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	clientIP, err := newClientIP()
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.Middleware(ratelimit.Config{
+		Skipper: isProbe,
+		Store:   ratelimit.NewMemoryStore(),
+		Default: limit,
+		Key:     clientIP,
+	}), nil
+}
+
+// newRateLimitMiddleware limits clients by RATE_LIMIT on every route,
+// RATE_LIMIT_ROUTES sets limits of particular routes, like "GET /restaurants=60/m; POST /restaurants=10/m".
+// It has to run after authentication, authenticated clients are told apart by their principals,
+// anonymous ones by their IPs, see newClientIP.
+func newRateLimitMiddleware() (echo.MiddlewareFunc, error) {
+	limit, err := ratelimit.ParseLimit(settings.GetSetting("RATE_LIMIT", defaultRateLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := ratelimit.ParseRouteLimits(settings.GetSetting("RATE_LIMIT_ROUTES", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	clientIP, err := newClientIP()
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.Middleware(ratelimit.Config{
+		Skipper: isProbe,
+		Store:   ratelimit.NewMemoryStore(),
+		Default: limit,
+		Routes:  routes,
+		Key:     rateLimitKey(clientIP),
+	}), nil
+}
+
+// newClientIP reads the address of the client from headers of TRUSTED_PROXIES only, like "10.0.0.1, 172.16.0.0/12",
+// the address of the connection is the client without them
+func newClientIP() (func(echo.Context) string, error) {
+	trustedProxies, err := ratelimit.ParseNetworks(settings.GetSetting("TRUSTED_PROXIES", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.ClientIP(trustedProxies), nil
+}
+
+func rateLimitKey(clientIP func(echo.Context) string) func(echo.Context) string {
+	return func(context echo.Context) string {
+		if principal := auth.PrincipalOf(context); principal != nil {
+			return principal.ID()
+		}
+
+		return "ip:" + clientIP(context)
+	}
+}
@@ -0,0 +1,35 @@
+package assembly
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// shutdownTimeout is how long requests in progress are served after the app is asked to stop,
+// storage operations of requests still running then are cancelled
+const shutdownTimeout = 10 * time.Second
+
+// cancelOnShutdown cancels the context of the request when shutdown is closed,
+// the context is cancelled as well when the client goes away
+func cancelOnShutdown(shutdown <-chan struct{}) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			ctx, cancel := context.WithCancel(request.Context())
+			defer cancel()
+
+			go func() {
+				select {
+				case <-shutdown:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			c.SetRequest(request.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package assembly
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type ShutdownTestSuite struct {
+	suite.Suite
+
+	shutdown    chan struct{}
+	echoContext echo.Context
+}
+
+func (suite *ShutdownTestSuite) SetupTest() {
+	suite.shutdown = make(chan struct{})
+	request := httptest.NewRequest(echo.GET, "/restaurants", nil)
+	suite.echoContext = echo.New().NewContext(request, httptest.NewRecorder())
+}
+
+func (suite *ShutdownTestSuite) TestCanceledOnShutdown() {
+	var err error
+	handler := func(c echo.Context) error {
+		close(suite.shutdown)
+		select {
+		case <-c.Request().Context().Done():
+			err = c.Request().Context().Err()
+		case <-time.After(time.Second):
+		}
+		return c.NoContent(http.StatusOK)
+	}
+
+	suite.Assertions.Nil(cancelOnShutdown(suite.shutdown)(handler)(suite.echoContext))
+	suite.Assertions.Equal(err, context.Canceled)
+}
+
+func (suite *ShutdownTestSuite) TestServedWithoutShutdown() {
+	var err error
+	handler := func(c echo.Context) error {
+		err = c.Request().Context().Err()
+		return c.NoContent(http.StatusOK)
+	}
+
+	suite.Assertions.Nil(cancelOnShutdown(suite.shutdown)(handler)(suite.echoContext))
+	suite.Assertions.Nil(err)
+	// the context of the request is released once it's served
+	suite.Assertions.Equal(suite.echoContext.Request().Context().Err(), context.Canceled)
+}
+
+func TestShutdownTestSuite(t *testing.T) {
+	suite.Run(t, new(ShutdownTestSuite))
+}
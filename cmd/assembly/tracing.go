@@ -0,0 +1,42 @@
+package assembly
+
+import (
+	"fmt"
+	"os"
+
+	"venues/cmd/settings"
+	"venues/pkg/logging"
+	"venues/pkg/tracing"
+)
+
+// serviceName tells spans of the app apart from spans of other services of the trace
+const serviceName = "venues"
+
+// configureTracer exports spans by TRACE_EXPORTER, "stdout" writes them as json lines to stdout,
+// "file" appends them to TRACE_FILE, spans aren't made at all unless it's set
+func configureTracer() error {
+	tracer := &tracing.Tracer{
+		Service: serviceName,
+		OnError: func(err error) {
+			logging.Default().WithError(err).Warn("Error exporting span")
+		},
+	}
+
+	switch exporter := settings.GetSetting("TRACE_EXPORTER", ""); exporter {
+	case "":
+		return nil
+	case "stdout":
+		tracer.Exporter = tracing.NewWriterExporter(os.Stdout)
+	case "file":
+		fileExporter, err := tracing.NewFileExporter(settings.MustGetSetting("TRACE_FILE"))
+		if err != nil {
+			return err
+		}
+		tracer.Exporter = fileExporter
+	default:
+		return fmt.Errorf("unknown TRACE_EXPORTER %q, it's stdout or file", exporter)
+	}
+
+	tracing.SetDefault(tracer)
+	return nil
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const APIKeyCommand = "apikey"
+
+var errAPIKeyUsage = errors.New("usage: venues apikey create NAME [admin|owner|viewer] | list | revoke ID")
+
+// APIKeyManager is what the command needs from repositories.APIKeyRepo
+type APIKeyManager interface {
+	Create(context.Context, *models.APIKey) (string, error)
+	List(context.Context) ([]models.APIKey, error)
+	Remove(context.Context, bson.ObjectId) error
+}
+
+// APIKeys runs "create NAME [ROLE]", "list" or "revoke ID",
+// a created key is printed only once, it can't be recovered later,
+// a key is created for a viewer unless the role is given
+func APIKeys(ctx context.Context, manager APIKeyManager, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errAPIKeyUsage
+	}
+
+	switch {
+	case args[0] == "create" && (len(args) == 2 || len(args) == 3) && strings.TrimSpace(args[1]) != "":
+		object := &models.APIKey{Name: strings.TrimSpace(args[1]), Role: auth.RoleViewer}
+		if len(args) == 3 {
+			if args[2] == "" || !auth.IsRole(args[2]) {
+				return errAPIKeyUsage
+			}
+			object.Role = args[2]
+		}
+
+		key, err := manager.Create(ctx, object)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "id:  %s\nkey: %s\n", object.ID.Hex(), key)
+		return nil
+
+	case args[0] == "list" && len(args) == 1:
+		keys, err := manager.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tNAME\tROLE\tCREATED")
+		for _, key := range keys {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", key.ID.Hex(), key.Name, key.Role, key.CreatedAt.Format(time.RFC3339))
+		}
+		return writer.Flush()
+
+	case args[0] == "revoke" && len(args) == 2 && bson.IsObjectIdHex(args[1]):
+		return manager.Remove(ctx, bson.ObjectIdHex(args[1]))
+	}
+
+	return errAPIKeyUsage
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type MockAPIKeyManager struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyManager) Create(ctx context.Context, object *models.APIKey) (string, error) {
+	args := m.Called(object)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAPIKeyManager) List(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyManager) Remove(ctx context.Context, id bson.ObjectId) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+type APIKeysTestSuite struct {
+	suite.Suite
+
+	manager *MockAPIKeyManager
+	out     *bytes.Buffer
+}
+
+func (suite *APIKeysTestSuite) SetupTest() {
+	suite.manager = &MockAPIKeyManager{}
+	suite.out = &bytes.Buffer{}
+}
+
+func (suite *APIKeysTestSuite) TestCreate() {
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	suite.manager.On("Create", &models.APIKey{Name: "deploy", Role: auth.RoleOwner}).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.APIKey).ID = id
+	}).Return("secret", nil)
+
+	err := APIKeys(context.Background(), suite.manager, []string{"create", "deploy", "owner"}, suite.out)
+
+	suite.manager.AssertExpectations(suite.T())
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(suite.out.String(), "id:  5a8ad983591b381c73797521\nkey: secret\n")
+}
+
+func (suite *APIKeysTestSuite) TestCreateViewer() {
+	suite.manager.On("Create", &models.APIKey{Name: "reader", Role: auth.RoleViewer}).Return("secret", nil)
+
+	err := APIKeys(context.Background(), suite.manager, []string{"create", "reader"}, suite.out)
+
+	suite.manager.AssertExpectations(suite.T())
+	suite.Assertions.Nil(err)
+}
+
+func (suite *APIKeysTestSuite) TestList() {
+	suite.manager.On("List").Return([]models.APIKey{{ID: bson.ObjectIdHex("5a8ad983591b381c73797521"), Name: "deploy", Role: auth.RoleAdmin}}, nil)
+
+	err := APIKeys(context.Background(), suite.manager, []string{"list"}, suite.out)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Contains(suite.out.String(), "ID  ")
+	suite.Assertions.Contains(suite.out.String(), "5a8ad983591b381c73797521  deploy  admin")
+}
+
+func (suite *APIKeysTestSuite) TestRevoke() {
+	suite.manager.On("Remove", bson.ObjectIdHex("5a8ad983591b381c73797521")).Return(errors.New("mocked error"))
+
+	err := APIKeys(context.Background(), suite.manager, []string{"revoke", "5a8ad983591b381c73797521"}, suite.out)
+
+	suite.manager.AssertExpectations(suite.T())
+	suite.Assertions.Error(err)
+}
+
+func (suite *APIKeysTestSuite) TestUsage() {
+	for _, args := range [][]string{
+		{},
+		{"create"},
+		{"create", " "},
+		{"create", "deploy", "root"},
+		{"create", "deploy", ""},
+		{"list", "all"},
+		{"revoke", "abc"},
+		{"rotate"},
+	} {
+		err := APIKeys(context.Background(), suite.manager, args, suite.out)
+
+		suite.Assertions.Equal(err, errAPIKeyUsage, args)
+	}
+}
+
+func TestAPIKeysTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeysTestSuite))
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"venues/cmd/models"
+	"venues/cmd/repositories"
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type AuditController struct {
+	Repo        repositories.AuditAccessor
+	MaxPageSize int
+}
+
+// List renders audit events to admins in the order they happened,
+// "restaurant_id" and "since" (RFC 3339 time) narrow them down
+func (controller *AuditController) List(context echo.Context) error {
+	if err := requireRole(context, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	filter, err := auditFilterParams(context)
+	if err != nil {
+		return err
+	}
+
+	pagination, err := paginationParams(context, controller.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if pagination.Cursor != "" {
+		return httperrors.New(http.StatusBadRequest, errAuditCursorMsg)
+	}
+
+	page, err := controller.Repo.List(context.Request().Context(), filter, pagination)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	return context.JSON(http.StatusOK, page)
+}
+
+func auditFilterParams(context echo.Context) (*models.AuditFilter, error) {
+	filter := &models.AuditFilter{}
+	var details []httperrors.Detail
+
+	if value := context.QueryParam(queryRestaurantIDParam); value != "" {
+		if bson.IsObjectIdHex(value) {
+			filter.RestaurantID = bson.ObjectIdHex(value)
+		} else {
+			details = append(details, httperrors.Detail{Field: queryRestaurantIDParam, Message: errFilterParamMsg})
+		}
+	}
+
+	if value := context.QueryParam(querySinceParam); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			filter.Since = since
+		} else {
+			details = append(details, httperrors.Detail{Field: querySinceParam, Message: errFilterParamMsg})
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, httperrors.New(http.StatusBadRequest, errAuditFilterMsg, details...)
+	}
+
+	return filter, nil
+}
+
+func NewAuditController() *AuditController {
+	return &AuditController{
+		Repo:        repositories.NewAuditRepo(),
+		MaxPageSize: settings.GetIntSetting("MAX_PAGE_SIZE", defaultMaxPageSize),
+	}
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type MockAuditRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepo) Create(ctx context.Context, object *models.AuditEvent) error {
+	args := m.Called(object)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepo) List(ctx context.Context, filter *models.AuditFilter, pagination *models.Pagination) (*models.AuditPage, error) {
+	args := m.Called(filter, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditPage), args.Error(1)
+}
+
+type AuditControllerTestSuite struct {
+	suite.Suite
+
+	controller  *AuditController
+	echoContext echo.Context
+	recorder    *httptest.ResponseRecorder
+}
+
+func (suite *AuditControllerTestSuite) SetupTest() {
+	suite.recorder = httptest.NewRecorder()
+	suite.request("/audit")
+}
+
+func (suite *AuditControllerTestSuite) request(target string) {
+	req := httptest.NewRequest(echo.GET, target, nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	auth.SetPrincipal(suite.echoContext, &auth.Principal{Subject: "admin", Method: auth.MethodJWT, Role: auth.RoleAdmin})
+}
+
+func (suite *AuditControllerTestSuite) serve(handler echo.HandlerFunc) {
+	if err := handler(suite.echoContext); err != nil {
+		httperrors.Handler(err, suite.echoContext)
+	}
+}
+
+func (suite *AuditControllerTestSuite) TestListSuccess() {
+	suite.request("/audit?restaurant_id=5a8ad983591b381c73797521&since=2018-02-19T10:00:00Z&page_size=5")
+
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	returnValue := &models.AuditPage{
+		Items:    []models.AuditEvent{{ID: bson.NewObjectId(), Actor: "10.0.0.1", Action: models.AuditUpdate, RestaurantID: id}},
+		Total:    1,
+		PageSize: 5,
+	}
+	mockRepo := &MockAuditRepo{}
+	suite.controller = &AuditController{Repo: mockRepo}
+	mockRepo.On(
+		"List",
+		&models.AuditFilter{RestaurantID: id, Since: time.Date(2018, 2, 19, 10, 0, 0, 0, time.UTC)},
+		&models.Pagination{Page: 1, PageSize: 5},
+	).Return(returnValue, nil)
+
+	suite.serve(suite.controller.List)
+
+	result := &models.AuditPage{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.Items[0].Action, models.AuditUpdate)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *AuditControllerTestSuite) TestListFail() {
+	for _, query := range []string{
+		"restaurant_id=abc",
+		"since=yesterday",
+		"cursor=abc",
+		"page=0",
+	} {
+		suite.SetupTest()
+		suite.request("/audit?" + query)
+		suite.controller = &AuditController{}
+
+		suite.serve(suite.controller.List)
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, query)
+	}
+}
+
+func (suite *AuditControllerTestSuite) TestListForbidden() {
+	auth.SetPrincipal(suite.echoContext, &auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleOwner})
+	suite.controller = &AuditController{}
+
+	suite.serve(suite.controller.List)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusForbidden)
+}
+
+func (suite *AuditControllerTestSuite) TestListFailService() {
+	mockRepo := &MockAuditRepo{}
+	suite.controller = &AuditController{Repo: mockRepo}
+	mockRepo.On("List", mock.Anything, mock.Anything).Return(nil, errors.New("mocked error"))
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
+}
+
+func TestAuditControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditControllerTestSuite))
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"net/http"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+)
+
+// requireRole lets only principals with one of the roles through
+func requireRole(context echo.Context, roles ...string) error {
+	principal := auth.PrincipalOf(context)
+	if principal == nil {
+		return httperrors.New(http.StatusUnauthorized, errAuthRequiredMsg)
+	}
+
+	for _, role := range roles {
+		if principal.Role == role {
+			return nil
+		}
+	}
+
+	return httperrors.New(http.StatusForbidden, errForbiddenMsg)
+}
+
+// authorize lets admins and owners of the restaurant change it,
+// the restaurant is read without the version of the query, so a stale version is reported by the change
+func (controller *RestaurantController) authorize(context echo.Context, query *models.Restaurant) error {
+	if err := requireRole(context, auth.RoleAdmin, auth.RoleOwner); err != nil {
+		return err
+	}
+
+	principal := auth.PrincipalOf(context)
+	if principal.Role == auth.RoleAdmin {
+		return nil
+	}
+
+	restaurant, err := controller.Repo.Get(context.Request().Context(), &models.Restaurant{ID: query.ID}, false)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	if !restaurant.IsOwnedBy(principal.ID()) {
+		return httperrors.New(http.StatusForbidden, errForbiddenMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func (suite *RestaurantControllerTestSuite) removeRequest() (*MockRepo, bson.ObjectId) {
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues(id.Hex())
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	return mockRepo, id
+}
+
+func (suite *RestaurantControllerTestSuite) TestAuthorizeOwner() {
+	mockRepo, id := suite.removeRequest()
+	suite.principal = &auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleOwner}
+	mockRepo.On("Get", &models.Restaurant{ID: id}, false).Return(&models.Restaurant{ID: id, OwnerIDs: []string{"api_key:bob", "jwt:ann"}}, nil)
+	mockRepo.On("Remove", &models.Restaurant{ID: id}).Return(nil)
+
+	suite.serve(suite.controller.Remove)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestAuthorizeFailNotOwner() {
+	mockRepo, id := suite.removeRequest()
+	suite.principal = &auth.Principal{Subject: "ann", Method: auth.MethodAPIKey, Role: auth.RoleOwner}
+	mockRepo.On("Get", &models.Restaurant{ID: id}, false).Return(&models.Restaurant{ID: id, OwnerIDs: []string{"jwt:ann"}}, nil)
+
+	suite.serve(suite.controller.Remove)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockRepo.AssertNotCalled(suite.T(), "Remove", mock.Anything)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusForbidden)
+}
+
+func (suite *RestaurantControllerTestSuite) TestAuthorizeFailNotFound() {
+	mockRepo, _ := suite.removeRequest()
+	suite.principal = &auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleOwner}
+	mockRepo.On("Get", mock.Anything, false).Return(nil, mgo.ErrNotFound)
+
+	suite.serve(suite.controller.Remove)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
+func (suite *RestaurantControllerTestSuite) TestAuthorizeFailRole() {
+	for _, test := range []struct {
+		principal *auth.Principal
+		status    int
+	}{
+		{nil, http.StatusUnauthorized},
+		{&auth.Principal{Subject: "ann", Method: auth.MethodJWT}, http.StatusForbidden},
+		{&auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleViewer}, http.StatusForbidden},
+	} {
+		for _, handler := range []func(*RestaurantController, echo.Context) error{
+			(*RestaurantController).Create,
+			(*RestaurantController).Update,
+			(*RestaurantController).Remove,
+			(*RestaurantController).AddDish,
+			(*RestaurantController).RemoveDish,
+			(*RestaurantController).Trash,
+			(*RestaurantController).Restore,
+			(*RestaurantController).AddOwner,
+		} {
+			suite.SetupTest()
+			suite.removeRequest()
+			suite.principal = test.principal
+
+			suite.serve(func(context echo.Context) error { return handler(suite.controller, context) })
+
+			suite.Assertions.Equal(suite.echoContext.Response().Status, test.status)
+		}
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestAuthorizeAdminOnly() {
+	for _, handler := range []func(*RestaurantController, echo.Context) error{
+		(*RestaurantController).Trash,
+		(*RestaurantController).Restore,
+		(*RestaurantController).AddOwner,
+		(*RestaurantController).RemoveOwner,
+	} {
+		suite.SetupTest()
+		suite.removeRequest()
+		suite.principal = &auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleOwner}
+
+		suite.serve(func(context echo.Context) error { return handler(suite.controller, context) })
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusForbidden)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestCreateByOwner() {
+	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(`{"name": "Name", "city": "City", "owner_ids": ["jwt:eve"]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.principal = &auth.Principal{Subject: "ann", Method: auth.MethodJWT, Role: auth.RoleOwner}
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Create", &models.Restaurant{Name: "Name", City: "City", OwnerIDs: []string{"jwt:ann"}}).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Create)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusCreated)
+}
+
+func (suite *RestaurantControllerTestSuite) TestAddOwnerSuccess() {
+	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(`{"owner_id": "jwt:ann"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	mockRepo, id := suite.removeRequest()
+	mockRepo.On("AddOwner", &models.Restaurant{ID: id}, "jwt:ann").Return(&models.Restaurant{ID: id, OwnerIDs: []string{"jwt:ann"}, Version: 2}, nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", &models.Owner{ID: "jwt:ann"}).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.AddOwner)
+
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.OwnerIDs, []string{"jwt:ann"})
+	suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"2"`)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRemoveOwnerSuccess() {
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	suite.echoContext.SetParamNames("restaurant_id", "owner_id")
+	suite.echoContext.SetParamValues(id.Hex(), "jwt:ann")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("RemoveOwner", &models.Restaurant{ID: id}, "jwt:ann").Return(&models.Restaurant{ID: id, Version: 3}, nil)
+
+	suite.serve(suite.controller.RemoveOwner)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
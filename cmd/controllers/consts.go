@@ -1,11 +1,81 @@
 package controllers
 
-import "fmt"
+import (
+	"fmt"
+
+	"venues/pkg/mergepatch"
+
+	"github.com/labstack/echo"
+)
 
 const (
 	queryOrderParam = "ordering"
 	queryPageParam  = "page"
+	queryMenuParam  = "menu"
+
+	queryPageSizeParam = "page_size"
+	queryCursorParam   = "cursor"
+	querySearchParam   = "q"
+
+	queryCityParam         = "city"
+	queryCityInParam       = "city_in"
+	queryNamePrefixParam   = "name_prefix"
+	queryRatingGteParam    = "rating_gte"
+	queryRatingLteParam    = "rating_lte"
+	queryHasMenuParam      = "has_menu"
+	queryDishPriceLteParam = "dish_price_lte"
+	queryNearParam         = "near"
+	queryRadiusParam       = "radius_m"
+
+	queryRestaurantIDParam = "restaurant_id"
+	querySinceParam        = "since"
+
+	defaultPageSize    = 20
+	defaultMaxPageSize = 100
+
+	restaurantIDParam = "restaurant_id"
+	dishIDParam       = "dish_id"
+	ownerIDParam      = "owner_id"
+
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
 )
 
-var errPageParamMsg = fmt.Sprintf("\"%s\" should be a positive integer\n", queryPageParam)
-var errObjectIdParamMsg = fmt.Sprint("ObjectIDs must be exactly 12 bytes long\n")
+var errPageParamMsg = fmt.Sprintf("\"%s\" should be a positive integer", queryPageParam)
+var errPageSizeParamMsg = "\"%s\" should be an integer from 1 to %d"
+var errCursorWithPageMsg = fmt.Sprintf("\"%s\" and \"%s\" can't be used together", queryCursorParam, queryPageParam)
+var errSearchCursorMsg = fmt.Sprintf("search results are ordered by relevance, use \"%s\" instead of \"%s\"", queryPageParam, queryCursorParam)
+var errNearOrderingMsg = fmt.Sprintf("restaurants \"%s\" a point are ordered by distance, \"%s\" and \"%s\" can't be used", queryNearParam, queryOrderParam, queryCursorParam)
+var errReviewCursorMsg = fmt.Sprintf("reviews are listed from the newest one, use \"%s\" instead of \"%s\"", queryPageParam, queryCursorParam)
+var errTrashCursorMsg = fmt.Sprintf("the trash is listed from the last removed restaurant, use \"%s\" instead of \"%s\"", queryPageParam, queryCursorParam)
+var errAuditCursorMsg = fmt.Sprintf("audit events are listed in the order they happened, use \"%s\" instead of \"%s\"", queryPageParam, queryCursorParam)
+var errMenuParamMsg = fmt.Sprintf("\"%s\" should be a boolean", queryMenuParam)
+var errPatchContentTypeMsg = fmt.Sprintf("patch should be \"%s\" or \"%s\"", mergepatch.MIMEMergePatchJSON, echo.MIMEApplicationJSON)
+
+const (
+	errFilterMsg       = "Invalid filter"
+	errFilterParamMsg  = "has invalid value"
+	errUnknownParamMsg = "is not a known query param"
+	errRequiredWithMsg = "is required with \"%s\""
+)
+
+const (
+	errOrderingMsg         = "Invalid ordering"
+	errOrderingFieldMsg    = "\"%s\" can't be used for ordering"
+	errOrderingRepeatedMsg = "\"%s\" is used more than once"
+)
+
+const (
+	errPatchMsg           = "Patch should be a json object"
+	errVersionConflictMsg = "Restaurant was changed, get it again and retry with its ETag"
+	errIfMatchRequiredMsg = "If-Match header with ETag of the restaurant is required"
+	errSearchMsg          = "Invalid search"
+	errAuditFilterMsg     = "Invalid audit filter"
+	errRequiredParamMsg   = "is required"
+	errNotFoundMsg        = "Not found"
+	errStorageMsg         = "Storage is unavailable"
+	errStorageTimeoutMsg  = "Storage didn't answer in time"
+	errAuthRequiredMsg    = "Authentication is required"
+	errForbiddenMsg       = "Not allowed"
+)
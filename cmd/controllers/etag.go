@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"venues/cmd/models"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+)
+
+// etag is the strong ETag of a restaurant version,
+// the menu and dishes of the restaurant share it
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setETag skips unknown versions, restaurants stored before versions were added have none
+func setETag(context echo.Context, version int) {
+	if version > 0 {
+		context.Response().Header().Set(headerETag, etag(version))
+	}
+}
+
+// setNextETag is for changes made with a version in the query,
+// they increment the version by one, so the new ETag is known without reading it
+func setNextETag(context echo.Context, query *models.Restaurant) {
+	if query.Version > 0 {
+		setETag(context, query.Version+1)
+	}
+}
+
+// notModified is true when If-None-Match has the ETag of the version,
+// weak tags are compared as strong ones, that's what If-None-Match does
+func notModified(context echo.Context, version int) bool {
+	value := context.Request().Header.Get(headerIfNoneMatch)
+	if value == "" || version == 0 {
+		return false
+	}
+
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifMatch sets the version of the query from If-Match header, so the repo changes only that version,
+// without the header any version is changed unless the controller requires it
+func (controller *RestaurantController) ifMatch(context echo.Context, query *models.Restaurant) error {
+	value := strings.TrimSpace(context.Request().Header.Get(headerIfMatch))
+	switch {
+	case value == "" && controller.RequireIfMatch:
+		return httperrors.New(http.StatusPreconditionRequired, errIfMatchRequiredMsg)
+	case value == "" || value == "*":
+		return nil
+	}
+
+	// weak and unknown tags never match, so they fail the same way as outdated ones
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`))
+	if err != nil || version < 1 || value != etag(version) {
+		return httperrors.New(http.StatusPreconditionFailed, errVersionConflictMsg)
+	}
+
+	query.Version = version
+	return nil
+}
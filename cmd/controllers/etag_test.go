@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"venues/cmd/models"
+	"venues/cmd/repositories"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func (suite *RestaurantControllerTestSuite) TestGetETag() {
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	for ifNoneMatch, status := range map[string]int{
+		"":               http.StatusOK,
+		`"2"`:            http.StatusOK,
+		`"3"`:            http.StatusNotModified,
+		`"2", W/"3"`:     http.StatusNotModified,
+		"*":              http.StatusNotModified,
+		`"3-something"`:  http.StatusOK,
+		`"1", "2", "30"`: http.StatusOK,
+	} {
+		suite.SetupTest()
+		suite.echoContext.Request().Header.Set(headerIfNoneMatch, ifNoneMatch)
+		suite.echoContext.SetParamNames("restaurant_id")
+		suite.echoContext.SetParamValues(id.Hex())
+
+		mockRepo := &MockRepo{}
+		suite.controller = &RestaurantController{Repo: mockRepo}
+		mockRepo.On("Get", &models.Restaurant{ID: id}, false).Return(&models.Restaurant{ID: id, Name: "Name", Version: 3}, nil)
+
+		suite.serve(suite.controller.Get)
+
+		mockRepo.AssertExpectations(suite.T())
+		suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"3"`)
+		suite.Assertions.Equal(suite.echoContext.Response().Status, status, ifNoneMatch)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetDishETag() {
+	suite.echoContext.Request().Header.Set(headerIfNoneMatch, `"7"`)
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"GetDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
+	).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Restaurant).Version = 7
+	}).Return(nil)
+
+	suite.serve(suite.controller.GetDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"7"`)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotModified)
+}
+
+func (suite *RestaurantControllerTestSuite) TestUpdateIfMatch() {
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(`{"name": "Name", "city": "City"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(headerIfMatch, `"2"`)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	mockRepo := &MockRepo{}
+	mockRepo.On(
+		"Update",
+		&models.Restaurant{ID: id, Version: 2},
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+	).Return(&models.Restaurant{ID: id, Name: "Name", City: "City", Version: 3}, nil)
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Update)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"3"`)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestUpdateFailIfMatch() {
+	for ifMatch, status := range map[string]int{
+		"":        http.StatusPreconditionRequired,
+		`W/"2"`:   http.StatusPreconditionFailed,
+		`"0"`:     http.StatusPreconditionFailed,
+		`2`:       http.StatusPreconditionFailed,
+		`"2","3"`: http.StatusPreconditionFailed,
+	} {
+		suite.SetupTest()
+		req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(`{"name": "Name", "city": "City"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(headerIfMatch, ifMatch)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+		suite.echoContext.SetParamNames("restaurant_id")
+		suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+		suite.controller = &RestaurantController{RequireIfMatch: true}
+
+		suite.serve(suite.controller.Update)
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, status, ifMatch)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestUpdateFailConflict() {
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(`{"name": "Name", "city": "City"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(headerIfMatch, `"2"`)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(nil, repositories.ErrVersionConflict)
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Update)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusPreconditionFailed)
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchUsesReadVersion() {
+	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(`{"name": "Patched"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	mockRepo := &MockRepo{}
+	mockRepo.On("Get", &models.Restaurant{ID: id}, false).Return(&models.Restaurant{ID: id, Name: "Name", City: "City", Version: 5}, nil)
+	mockRepo.On("Update", &models.Restaurant{ID: id, Version: 5}, mock.Anything).Return(nil, repositories.ErrVersionConflict)
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Patch)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusPreconditionFailed)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRemoveDishIfMatch() {
+	suite.echoContext.Request().Header.Set(headerIfMatch, `"4"`)
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"RemoveDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return obj.Version == 4 }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
+	).Return(nil)
+
+	suite.serve(suite.controller.RemoveDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"5"`)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRemoveFailIfMatch() {
+	suite.echoContext.Request().Header.Set(headerIfMatch, `"4"`)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Remove", &models.Restaurant{ID: bson.ObjectIdHex("5a8ad983591b381c73797521"), Version: 4}).Return(repositories.ErrVersionConflict)
+
+	suite.serve(suite.controller.Remove)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusPreconditionFailed)
+}
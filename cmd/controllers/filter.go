@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"venues/cmd/models"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+)
+
+// listParams are query params of the listing that are not filters
+var listParams = map[string]bool{
+	queryOrderParam:    true,
+	queryPageParam:     true,
+	queryPageSizeParam: true,
+	queryCursorParam:   true,
+}
+
+// restaurantFilter parses filter query params of the listing,
+// unknown params are rejected, so a typo doesn't silently list everything
+func restaurantFilter(context echo.Context) (*models.RestaurantFilter, error) {
+	filter := &models.RestaurantFilter{}
+	var details []httperrors.Detail
+
+	params := context.QueryParams()
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	// sorted, so details are listed in the same order every time
+	sort.Strings(names)
+
+	for _, name := range names {
+		if listParams[name] {
+			continue
+		}
+
+		values := params[name]
+		value := values[0]
+		var err error
+		switch name {
+		case queryCityParam:
+			filter.City = value
+		case queryCityInParam:
+			filter.CityIn = splitList(values)
+		case queryNamePrefixParam:
+			filter.NamePrefix = value
+		case queryRatingGteParam:
+			filter.RatingGte, err = parseRating(value)
+		case queryRatingLteParam:
+			filter.RatingLte, err = parseRating(value)
+		case queryHasMenuParam:
+			var hasMenu bool
+			hasMenu, err = strconv.ParseBool(value)
+			filter.HasMenu = &hasMenu
+		case queryDishPriceLteParam:
+			var price int
+			price, err = strconv.Atoi(value)
+			filter.DishPriceLte = &price
+		case queryNearParam:
+			filter.Near, err = parseLatLng(value)
+		case queryRadiusParam:
+			var radius float64
+			radius, err = strconv.ParseFloat(value, 64)
+			filter.RadiusM = &radius
+		default:
+			details = append(details, httperrors.Detail{Field: name, Message: errUnknownParamMsg})
+			continue
+		}
+
+		if err != nil {
+			details = append(details, httperrors.Detail{Field: name, Message: errFilterParamMsg})
+		}
+	}
+
+	// radius has to be explicit, so a typo in it doesn't list the whole world
+	switch {
+	case len(params[queryNearParam]) > 0 && len(params[queryRadiusParam]) == 0:
+		details = append(details, httperrors.Detail{Field: queryRadiusParam, Message: fmt.Sprintf(errRequiredWithMsg, queryNearParam)})
+	case len(params[queryRadiusParam]) > 0 && len(params[queryNearParam]) == 0:
+		details = append(details, httperrors.Detail{Field: queryNearParam, Message: fmt.Sprintf(errRequiredWithMsg, queryRadiusParam)})
+	}
+
+	if len(details) > 0 {
+		return nil, httperrors.New(http.StatusBadRequest, errFilterMsg, details...)
+	}
+
+	if err := context.Validate(filter); err != nil {
+		return nil, httperrors.BadRequest(err)
+	}
+
+	return filter, nil
+}
+
+// parseLatLng reads "lat,lng" the way maps show points,
+// the result is [longitude, latitude] as GeoJSON has it
+func parseLatLng(value string) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return nil, strconv.ErrSyntax
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return []float64{longitude, latitude}, nil
+}
+
+func parseRating(value string) (*float32, error) {
+	rating, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	result := float32(rating)
+	return &result, nil
+}
+
+// restaurantOrdering parses comma separated ordering like "-rating,name",
+// only fields of models.RestaurantOrderings are allowed
+func restaurantOrdering(context echo.Context) (models.Ordering, error) {
+	value := context.QueryParam(queryOrderParam)
+	if value == "" {
+		return nil, nil
+	}
+
+	var ordering models.Ordering
+	var details []httperrors.Detail
+	used := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		key := models.OrderingKey{Descending: strings.HasPrefix(name, "-")}
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := models.RestaurantOrderings[name]
+		switch {
+		case !ok:
+			details = append(details, httperrors.Detail{Field: queryOrderParam, Message: fmt.Sprintf(errOrderingFieldMsg, name)})
+		case used[field]:
+			details = append(details, httperrors.Detail{Field: queryOrderParam, Message: fmt.Sprintf(errOrderingRepeatedMsg, name)})
+		default:
+			used[field] = true
+			key.Field = field
+			ordering = append(ordering, key)
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, httperrors.New(http.StatusBadRequest, errOrderingMsg, details...)
+	}
+
+	return ordering, nil
+}
+
+// splitList accepts both "a,b" and repeated params, empty items are dropped
+func splitList(values []string) []string {
+	var result []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+
+	return result
+}
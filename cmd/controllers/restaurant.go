@@ -1,10 +1,22 @@
 package controllers
 
 import (
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"venues/cmd/repositories"
 
 	"venues/cmd/models"
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+	"venues/pkg/logging"
+	"venues/pkg/mergepatch"
+	"venues/pkg/pathparams"
+	"venues/pkg/tracing"
 
 	"strconv"
 
@@ -14,129 +26,556 @@ import (
 )
 
 type RestaurantController struct {
-	Repo repositories.RestaurantAccessor
+	Repo        repositories.RestaurantAccessor
+	MaxPageSize int
+	// RequireIfMatch rejects changes without If-Match header,
+	// otherwise they are conditional only when the header is sent
+	RequireIfMatch bool
 }
 
 // I'm not checking for empty list cause We actually don't wanna see 204,
 // Easier will get empty list and 200
 func (controller *RestaurantController) List(context echo.Context) error {
-	var err error
+	filter, ordering, pagination, err := controller.listParams(context)
+	if err != nil {
+		return err
+	}
+
+	page, err := controller.Repo.List(context.Request().Context(), filter, ordering, pagination)
+	if err != nil {
+		if err == repositories.ErrInvalidCursor {
+			return httperrors.New(http.StatusBadRequest, err.Error())
+		}
+
+		return storageError(context, err)
+	}
+
+	return renderJSON(context, http.StatusOK, page)
+}
+
+// listParams are traced apart from the query and rendering to tell which of them makes listing slow
+func (controller *RestaurantController) listParams(context echo.Context) (*models.RestaurantFilter, models.Ordering, *models.Pagination, error) {
+	_, span := tracing.Start(context.Request().Context(), "bind")
+	defer span.End()
+
+	filter, err := restaurantFilter(context)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ordering, err := restaurantOrdering(context)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pagination, err := paginationParams(context, controller.MaxPageSize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if filter.Near != nil && (ordering != nil || pagination.Cursor != "") {
+		return nil, nil, nil, httperrors.New(http.StatusBadRequest, errNearOrderingMsg)
+	}
+
+	return filter, ordering, pagination, nil
+}
+
+// Search lists restaurants matching "q" by relevance,
+// matched dishes are listed with every restaurant
+func (controller *RestaurantController) Search(context echo.Context) error {
+	text := strings.TrimSpace(context.QueryParam(querySearchParam))
+	if text == "" {
+		return httperrors.New(http.StatusBadRequest, errSearchMsg, httperrors.Detail{Field: querySearchParam, Message: errRequiredParamMsg})
+	}
+
+	pagination, err := paginationParams(context, controller.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if pagination.Cursor != "" {
+		return httperrors.New(http.StatusBadRequest, errSearchCursorMsg)
+	}
+
+	page, err := controller.Repo.Search(context.Request().Context(), text, pagination)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	return renderJSON(context, http.StatusOK, page)
+}
+
+// paginationParams reads page, page_size and cursor query params,
+// without them the first page of default size is listed
+func paginationParams(context echo.Context, maxPageSize int) (*models.Pagination, error) {
+	pagination := &models.Pagination{
+		Page:     1,
+		PageSize: defaultPageSize,
+		Cursor:   context.QueryParam(queryCursorParam),
+	}
 
-	filter := &models.Restaurant{}
-	if err = context.Bind(filter); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+	if maxPageSize == 0 {
+		maxPageSize = defaultMaxPageSize
+	}
+	if pagination.PageSize > maxPageSize {
+		pagination.PageSize = maxPageSize
+	}
+
+	if queryPageSize := context.QueryParam(queryPageSizeParam); queryPageSize != "" {
+		pageSize, err := strconv.ParseUint(queryPageSize, 10, 32)
+		if err != nil || pageSize == 0 || int(pageSize) > maxPageSize {
+			return nil, httperrors.New(http.StatusBadRequest, fmt.Sprintf(errPageSizeParamMsg, queryPageSizeParam, maxPageSize))
+		}
+		pagination.PageSize = int(pageSize)
 	}
 
-	var page uint64
 	if queryPage := context.QueryParam(queryPageParam); queryPage != "" {
-		page, err = strconv.ParseUint(queryPage, 10, 32)
+		if pagination.Cursor != "" {
+			return nil, httperrors.New(http.StatusBadRequest, errCursorWithPageMsg)
+		}
+
+		page, err := strconv.ParseUint(queryPage, 10, 32)
+		if err != nil || page == 0 {
+			return nil, httperrors.New(http.StatusBadRequest, errPageParamMsg)
+		}
+		pagination.Page = int(page)
+	}
+
+	return pagination, nil
+}
+
+// Get renders the menu as well when "menu" query param is true
+func (controller *RestaurantController) Get(context echo.Context) error {
+	query := restaurantParam(context)
+
+	var withMenu bool
+	if queryMenu := context.QueryParam(queryMenuParam); queryMenu != "" {
+		var err error
+		withMenu, err = strconv.ParseBool(queryMenu)
 		if err != nil {
-			return context.String(http.StatusBadRequest, errPageParamMsg)
+			return httperrors.New(http.StatusBadRequest, errMenuParamMsg)
 		}
 	}
 
-	restaurants, err := controller.Repo.List(filter, context.QueryParam(queryOrderParam), int(page))
+	restaurant, err := controller.Repo.Get(context.Request().Context(), query, withMenu)
 	if err != nil {
-		return context.NoContent(http.StatusServiceUnavailable)
+		return storageError(context, err)
 	}
 
-	return context.JSON(http.StatusOK, restaurants)
+	setETag(context, restaurant.Version)
+	if notModified(context, restaurant.Version) {
+		return context.NoContent(http.StatusNotModified)
+	}
+
+	if withMenu {
+		return context.JSON(http.StatusOK, &models.RestaurantWithMenu{Restaurant: restaurant, Menu: restaurant.Menu})
+	}
+
+	return context.JSON(http.StatusOK, restaurant)
 }
 
+// Create makes an owner the only owner of the restaurant, admins grant owners later
 func (controller *RestaurantController) Create(context echo.Context) error {
+	if err := requireRole(context, auth.RoleAdmin, auth.RoleOwner); err != nil {
+		return err
+	}
+
 	restaurant := &models.Restaurant{}
 	if err := context.Bind(restaurant); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+		return httperrors.BadRequest(err)
+	}
+
+	// a new restaurant isn't in the trash and has no owners but the creator
+	restaurant.OwnerIDs = nil
+	restaurant.DeletedAt = nil
+	if principal := auth.PrincipalOf(context); principal.Role == auth.RoleOwner {
+		restaurant.OwnerIDs = []string{principal.ID()}
 	}
 
 	if err := context.Validate(restaurant); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+		return httperrors.BadRequest(err)
 	}
 
-	if err := controller.Repo.Create(restaurant); err != nil {
-		return context.NoContent(http.StatusServiceUnavailable)
+	if err := controller.Repo.Create(context.Request().Context(), restaurant); err != nil {
+		return storageError(context, err)
 	}
 
-	return context.NoContent(http.StatusOK)
+	setETag(context, restaurant.Version)
+	return created(context, restaurant.ID, restaurant)
 }
 
+// Update replaces the whole restaurant, so the body has to be a valid restaurant,
+// fields missing from it are removed, the menu and the rating are kept
 func (controller *RestaurantController) Update(context echo.Context) error {
-	query := &models.Restaurant{ID: bson.ObjectId(context.Param("restaurant_id"))}
-	update := &models.Restaurant{}
-	if err := context.Bind(update); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+	query := restaurantParam(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
 	}
 
-	if err := controller.Repo.Update(query, update); err != nil {
-		if err == mgo.ErrNotFound {
-			return context.NoContent(http.StatusNotFound)
-		}
+	restaurant := &models.Restaurant{}
+	if err := context.Bind(restaurant); err != nil {
+		return httperrors.BadRequest(err)
+	}
+	// the id comes from the path, not from the body
+	restaurant.ID = query.ID
+
+	return controller.saveRestaurant(context, query, restaurant)
+}
 
-		return context.NoContent(http.StatusServiceUnavailable)
+// Patch applies the body as JSON Merge Patch to the stored restaurant,
+// null removes a field, the result is validated as a whole.
+// The patched version is the one read, so concurrent changes are not overwritten
+func (controller *RestaurantController) Patch(context echo.Context) error {
+	query := restaurantParam(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
 	}
 
-	return context.NoContent(http.StatusOK)
+	contentType := context.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, mergepatch.MIMEMergePatchJSON) && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return httperrors.New(http.StatusUnsupportedMediaType, errPatchContentTypeMsg)
+	}
+
+	stored, err := controller.Repo.Get(context.Request().Context(), query, false)
+	if err != nil {
+		return storageError(context, err)
+	}
+	query.Version = stored.Version
+
+	patch, err := ioutil.ReadAll(context.Request().Body)
+	if err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	document, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	patched, err := mergepatch.Apply(document, patch)
+	if err != nil {
+		return httperrors.New(http.StatusBadRequest, errPatchMsg)
+	}
+
+	restaurant := &models.Restaurant{}
+	if err := json.Unmarshal(patched, restaurant); err != nil {
+		return httperrors.New(http.StatusBadRequest, errPatchMsg)
+	}
+	// the id comes from the path, not from the body
+	restaurant.ID = query.ID
+
+	return controller.saveRestaurant(context, query, restaurant)
+}
+
+func (controller *RestaurantController) saveRestaurant(context echo.Context, query *models.Restaurant, restaurant *models.Restaurant) error {
+	if err := context.Validate(restaurant); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	updated, err := controller.Repo.Update(context.Request().Context(), query, restaurant)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, updated.Version)
+	return context.JSON(http.StatusOK, updated)
 }
 
 func (controller *RestaurantController) Remove(context echo.Context) error {
-	query := &models.Restaurant{ID: bson.ObjectId(context.Param("restaurant_id"))}
-	if err := controller.Repo.Remove(query); err != nil {
-		if err == mgo.ErrNotFound {
-			return context.NoContent(http.StatusNotFound)
-		}
+	query := restaurantParam(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
 
-		return context.NoContent(http.StatusServiceUnavailable)
+	if err := controller.Repo.Remove(context.Request().Context(), query); err != nil {
+		return storageError(context, err)
 	}
 
 	return context.NoContent(http.StatusOK)
 }
 
+// AddOwner lets the principal with "owner_id" of the body change the restaurant,
+// ids of principals are like "jwt:ann" or "api_key:deploy"
+func (controller *RestaurantController) AddOwner(context echo.Context) error {
+	query := restaurantParam(context)
+	if err := requireRole(context, auth.RoleAdmin); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	owner := &models.Owner{}
+	if err := context.Bind(owner); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	if err := context.Validate(owner); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	restaurant, err := controller.Repo.AddOwner(context.Request().Context(), query, owner.ID)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, restaurant.Version)
+	return context.JSON(http.StatusOK, restaurant)
+}
+
+// RemoveOwner takes changing the restaurant away from the principal with "owner_id" of the path
+func (controller *RestaurantController) RemoveOwner(context echo.Context) error {
+	query := restaurantParam(context)
+	if err := requireRole(context, auth.RoleAdmin); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	restaurant, err := controller.Repo.RemoveOwner(context.Request().Context(), query, context.Param(ownerIDParam))
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, restaurant.Version)
+	return context.JSON(http.StatusOK, restaurant)
+}
+
+// Trash lists removed restaurants that are not purged yet
+func (controller *RestaurantController) Trash(context echo.Context) error {
+	if err := requireRole(context, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	pagination, err := paginationParams(context, controller.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if pagination.Cursor != "" {
+		return httperrors.New(http.StatusBadRequest, errTrashCursorMsg)
+	}
+
+	page, err := controller.Repo.ListTrash(context.Request().Context(), pagination)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	return renderJSON(context, http.StatusOK, page)
+}
+
+// Restore takes a removed restaurant out of the trash,
+// a restaurant that is not in the trash is not found
+func (controller *RestaurantController) Restore(context echo.Context) error {
+	query := restaurantParam(context)
+	if err := requireRole(context, auth.RoleAdmin); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	restaurant, err := controller.Repo.Restore(context.Request().Context(), query)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, restaurant.Version)
+	return context.JSON(http.StatusOK, restaurant)
+}
+
 func (controller *RestaurantController) AddDish(context echo.Context) error {
-	defer controller.ObjectIDErrorHandler(context)
+	query := restaurantParam(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
 
-	query := &models.Restaurant{ID: bson.ObjectIdHex(context.Param("restaurant_id"))}
 	dish := &models.Dish{}
 	if err := context.Bind(dish); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+		return httperrors.BadRequest(err)
 	}
 
 	if err := context.Validate(dish); err != nil {
-		return context.String(http.StatusBadRequest, err.Error())
+		return httperrors.BadRequest(err)
 	}
 
-	if err := controller.Repo.AddDish(query, dish); err != nil {
-		if err == mgo.ErrNotFound {
-			return context.NoContent(http.StatusNotFound)
-		}
-
-		context.Logger().Error(err.Error())
-		return context.NoContent(http.StatusServiceUnavailable)
+	if err := controller.Repo.AddDish(context.Request().Context(), query, dish); err != nil {
+		return storageError(context, err)
 	}
 
-	return context.NoContent(http.StatusOK)
+	setNextETag(context, query)
+	return created(context, dish.ID, dish)
 }
 
 func (controller *RestaurantController) ListDish(context echo.Context) error {
-	defer controller.ObjectIDErrorHandler(context)
-
-	query := &models.Restaurant{ID: bson.ObjectIdHex(context.Param("restaurant_id"))}
+	query := restaurantParam(context)
 	menu := &models.Menu{}
-	if err := controller.Repo.ListDish(query, menu); err != nil {
-		return context.NoContent(http.StatusServiceUnavailable)
+	if err := controller.Repo.ListDish(context.Request().Context(), query, menu); err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, menu.Version)
+	if notModified(context, menu.Version) {
+		return context.NoContent(http.StatusNotModified)
 	}
 
 	return context.JSON(http.StatusOK, menu)
 }
 
-func (controller *RestaurantController) ObjectIDErrorHandler(context echo.Context) error {
-	if r := recover(); r != nil {
-		context.Logger().Error(r)
-		return context.String(http.StatusBadRequest, errObjectIdParamMsg)
+func (controller *RestaurantController) GetDish(context echo.Context) error {
+	query, dish := dishParams(context)
+
+	if err := controller.Repo.GetDish(context.Request().Context(), query, dish); err != nil {
+		return storageError(context, err)
+	}
+
+	setETag(context, query.Version)
+	if notModified(context, query.Version) {
+		return context.NoContent(http.StatusNotModified)
+	}
+
+	return context.JSON(http.StatusOK, dish)
+}
+
+// UpdateDish replaces the whole dish, so the body has to be a valid dish
+func (controller *RestaurantController) UpdateDish(context echo.Context) error {
+	query, dish := dishParams(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	dishID := dish.ID
+	if err := context.Bind(dish); err != nil {
+		return httperrors.BadRequest(err)
+	}
+	// the id comes from the path, not from the body
+	dish.ID = dishID
+
+	return controller.saveDish(context, query, dish)
+}
+
+// PatchDish applies the body on top of the stored dish,
+// GetDish sets the version it's read from, so the dish is saved only if it's not changed meanwhile
+func (controller *RestaurantController) PatchDish(context echo.Context) error {
+	query, dish := dishParams(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	if err := controller.Repo.GetDish(context.Request().Context(), query, dish); err != nil {
+		return storageError(context, err)
+	}
+
+	dishID := dish.ID
+	if err := context.Bind(dish); err != nil {
+		return httperrors.BadRequest(err)
+	}
+	// the id comes from the path, not from the body
+	dish.ID = dishID
+
+	return controller.saveDish(context, query, dish)
+}
+
+func (controller *RestaurantController) saveDish(context echo.Context, query *models.Restaurant, dish *models.Dish) error {
+	if err := context.Validate(dish); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	if err := controller.Repo.UpdateDish(context.Request().Context(), query, dish); err != nil {
+		return storageError(context, err)
+	}
+
+	setNextETag(context, query)
+	return context.JSON(http.StatusOK, dish)
+}
+
+func (controller *RestaurantController) RemoveDish(context echo.Context) error {
+	query, dish := dishParams(context)
+	if err := controller.authorize(context, query); err != nil {
+		return err
+	}
+	if err := controller.ifMatch(context, query); err != nil {
+		return err
+	}
+
+	if err := controller.Repo.RemoveDish(context.Request().Context(), query, dish); err != nil {
+		return storageError(context, err)
+	}
+
+	setNextETag(context, query)
+	return context.NoContent(http.StatusOK)
+}
+
+// storageError maps repository errors to http errors,
+// anything but mgo.ErrNotFound and a version conflict means storage is unavailable
+func storageError(context echo.Context, err error) error {
+	switch err {
+	case mgo.ErrNotFound:
+		return httperrors.New(http.StatusNotFound, errNotFoundMsg)
+	case repositories.ErrVersionConflict:
+		return httperrors.New(http.StatusPreconditionFailed, errVersionConflictMsg)
+	case gocontext.DeadlineExceeded:
+		logging.From(context).WithError(err).Warn("Storage timed out")
+		return httperrors.New(http.StatusGatewayTimeout, errStorageTimeoutMsg)
+	case gocontext.Canceled:
+		// the client is gone or the app is shutting down, it's not a failure of the storage
+		logging.From(context).Info("Request was canceled")
+		return httperrors.New(http.StatusServiceUnavailable, errStorageMsg)
 	}
 
-	return context.NoContent(http.StatusServiceUnavailable)
+	logging.From(context).WithError(err).Error("Error accessing storage")
+	return httperrors.New(http.StatusServiceUnavailable, errStorageMsg)
+}
+
+// renderJSON traces serialization, which takes a good part of serving big pages
+func renderJSON(context echo.Context, code int, object interface{}) error {
+	_, span := tracing.Start(context.Request().Context(), "render")
+	defer span.End()
+
+	return context.JSON(code, object)
+}
+
+// created renders a new resource with Location header
+// pointing to it under the collection path of the request
+func created(context echo.Context, id bson.ObjectId, object interface{}) error {
+	location := fmt.Sprintf("%s/%s", strings.TrimSuffix(context.Request().URL.Path, "/"), id.Hex())
+	context.Response().Header().Set(echo.HeaderLocation, location)
+
+	return context.JSON(http.StatusCreated, object)
+}
+
+// restaurantParam builds a query by restaurant id of the path,
+// the id is validated by pathparams.ObjectIDs middleware of the route
+func restaurantParam(context echo.Context) *models.Restaurant {
+	return &models.Restaurant{ID: pathparams.ObjectID(context, restaurantIDParam)}
+}
+
+// dishParams is restaurantParam for routes with dish id
+func dishParams(context echo.Context) (*models.Restaurant, *models.Dish) {
+	return restaurantParam(context), &models.Dish{ID: pathparams.ObjectID(context, dishIDParam)}
 }
 
 func NewRestaurantController() *RestaurantController {
-	return &RestaurantController{Repo: repositories.NewRestaurantRepo()}
+	return &RestaurantController{
+		Repo:           repositories.NewRestaurantRepo(),
+		MaxPageSize:    settings.GetIntSetting("MAX_PAGE_SIZE", defaultMaxPageSize),
+		RequireIfMatch: settings.GetBoolSetting("REQUIRE_IF_MATCH", false),
+	}
 }
@@ -1,10 +1,16 @@
 package controllers
 
 import (
+	"context"
 	"venues/cmd/models"
 
 	"net/http/httptest"
 	"venues/cmd/fixtures"
+	"venues/cmd/repositories"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+	"venues/pkg/mergepatch"
+	"venues/pkg/pathparams"
 
 	"errors"
 	"net/http"
@@ -15,11 +21,13 @@ import (
 	"strings"
 
 	"fmt"
+	"time"
 
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
 type MockBinder struct {
@@ -31,190 +39,658 @@ func (m *MockBinder) Bind(i interface{}, c echo.Context) error {
 	return args.Error(0)
 }
 
+type MockValidator struct {
+	mock.Mock
+}
+
+func (m *MockValidator) Validate(i interface{}) error {
+	args := m.Called(i)
+	return args.Error(0)
+}
+
 type MockRepo struct {
 	mock.Mock
 }
 
-func (m *MockRepo) List(filter *models.Restaurant, ordering string, page int) ([]models.Restaurant, error) {
-	args := m.Called(filter, ordering, page)
-	return args.Get(0).([]models.Restaurant), args.Error(1)
+func (m *MockRepo) List(ctx context.Context, filter *models.RestaurantFilter, ordering models.Ordering, pagination *models.Pagination) (*models.RestaurantPage, error) {
+	args := m.Called(filter, ordering, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RestaurantPage), args.Error(1)
 }
 
-func (m *MockRepo) Create(object *models.Restaurant) error {
+func (m *MockRepo) Search(ctx context.Context, text string, pagination *models.Pagination) (*models.SearchPage, error) {
+	args := m.Called(text, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchPage), args.Error(1)
+}
+
+func (m *MockRepo) Get(ctx context.Context, query *models.Restaurant, withMenu bool) (*models.Restaurant, error) {
+	args := m.Called(query, withMenu)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Restaurant), args.Error(1)
+}
+
+func (m *MockRepo) Create(ctx context.Context, object *models.Restaurant) error {
 	args := m.Called(object)
 	return args.Error(0)
 }
 
-func (m *MockRepo) Update(query *models.Restaurant, object *models.Restaurant) error {
+func (m *MockRepo) Update(ctx context.Context, query *models.Restaurant, object *models.Restaurant) (*models.Restaurant, error) {
 	args := m.Called(query, object)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Restaurant), args.Error(1)
 }
 
-func (m *MockRepo) Remove(query *models.Restaurant) error {
+func (m *MockRepo) Remove(ctx context.Context, query *models.Restaurant) error {
 	args := m.Called(query)
 	return args.Error(0)
 }
 
-func (m *MockRepo) AddDish(query *models.Restaurant, object *models.Dish) error {
+func (m *MockRepo) AddDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
 	args := m.Called(query, object)
 	return args.Error(0)
 }
 
+func (m *MockRepo) ListDish(ctx context.Context, query *models.Restaurant, objects *models.Menu) error {
+	args := m.Called(query, objects)
+	return args.Error(0)
+}
+
+func (m *MockRepo) GetDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	args := m.Called(query, object)
+	return args.Error(0)
+}
+
+func (m *MockRepo) UpdateDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	args := m.Called(query, object)
+	return args.Error(0)
+}
+
+func (m *MockRepo) RemoveDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	args := m.Called(query, object)
+	return args.Error(0)
+}
+
+func (m *MockRepo) ListTrash(ctx context.Context, pagination *models.Pagination) (*models.RestaurantPage, error) {
+	args := m.Called(pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RestaurantPage), args.Error(1)
+}
+
+func (m *MockRepo) Restore(ctx context.Context, query *models.Restaurant) (*models.Restaurant, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Restaurant), args.Error(1)
+}
+
+func (m *MockRepo) AddOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	args := m.Called(query, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Restaurant), args.Error(1)
+}
+
+func (m *MockRepo) RemoveOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	args := m.Called(query, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Restaurant), args.Error(1)
+}
+
+func (m *MockRepo) SetRating(ctx context.Context, query *models.Restaurant, rating *models.Rating) error {
+	args := m.Called(query, rating)
+	return args.Error(0)
+}
+
+func (m *MockRepo) Purge(ctx context.Context, before time.Time) ([]bson.ObjectId, error) {
+	args := m.Called(before)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]bson.ObjectId), args.Error(1)
+}
+
 type RestaurantControllerTestSuite struct {
 	suite.Suite
 
 	controller  *RestaurantController
 	echoContext echo.Context
 	recorder    *httptest.ResponseRecorder
+	principal   *auth.Principal
 }
 
 func (suite *RestaurantControllerTestSuite) SetupTest() {
 	req := httptest.NewRequest(echo.GET, "/", nil)
 	suite.recorder = httptest.NewRecorder()
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.principal = &auth.Principal{Subject: "admin", Method: auth.MethodAPIKey, Role: auth.RoleAdmin}
+}
+
+// serve runs the handler behind path params middleware
+// and renders its error the way the app does, the request is made by suite.principal
+func (suite *RestaurantControllerTestSuite) serve(handler echo.HandlerFunc) {
+	if suite.principal != nil {
+		auth.SetPrincipal(suite.echoContext, suite.principal)
+	}
+
+	middleware := pathparams.ObjectIDs(restaurantIDParam, dishIDParam)
+	if err := middleware(handler)(suite.echoContext); err != nil {
+		httperrors.Handler(err, suite.echoContext)
+	}
 }
 
 func (suite *RestaurantControllerTestSuite) TestListSuccess() {
-	var emptyData []models.Restaurant
-	for _, returnValue := range [][]models.Restaurant{
+	for _, items := range [][]models.Restaurant{
 		fixtures.SimpleRestaurantSet(),
-		emptyData,
+		{},
 	} {
+		suite.SetupTest()
+		returnValue := &models.RestaurantPage{Items: items, Total: len(items), PageSize: defaultPageSize}
+
 		mockRepo := &MockRepo{}
 		suite.controller = &RestaurantController{Repo: mockRepo}
 		mockRepo.On(
 			"List",
-			mock.MatchedBy(func(i *models.Restaurant) bool { return true }),
-			"",
-			0,
+			mock.MatchedBy(func(i *models.RestaurantFilter) bool { return true }),
+			models.Ordering(nil),
+			&models.Pagination{Page: 1, PageSize: defaultPageSize},
 		).Return(returnValue, nil)
-		mockBinder := &MockBinder{}
-		mockBinder.On(
-			"Bind",
-			mock.MatchedBy(func(i interface{}) bool { return true }),
-			suite.echoContext,
-		).Return(nil)
+		mockValidator := &MockValidator{}
+		mockValidator.On("Validate", mock.Anything).Return(nil)
 
-		suite.echoContext.Echo().Binder = mockBinder
+		suite.echoContext.Echo().Validator = mockValidator
 
-		suite.controller.List(suite.echoContext)
+		suite.serve(suite.controller.List)
 
-		var resultValue []models.Restaurant
-		json.NewDecoder(suite.recorder.Body).Decode(&resultValue)
+		resultValue := &models.RestaurantPage{}
+		json.NewDecoder(suite.recorder.Body).Decode(resultValue)
 
 		mockRepo.AssertExpectations(suite.T())
-		mockBinder.AssertExpectations(suite.T())
+		mockValidator.AssertExpectations(suite.T())
 		suite.Assertions.Equal(resultValue, returnValue)
 		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
 	}
 }
 
 func (suite *RestaurantControllerTestSuite) TestListFailService() {
-	var returnValue []models.Restaurant
-
 	mockRepo := &MockRepo{}
 	suite.controller = &RestaurantController{Repo: mockRepo}
 	mockRepo.On(
 		"List",
-		mock.MatchedBy(func(i *models.Restaurant) bool { return true }),
-		"",
-		0,
-	).Return(returnValue, errors.New("mocked error"))
-	mockBinder := &MockBinder{}
-	mockBinder.On(
-		"Bind",
-		mock.MatchedBy(func(i interface{}) bool { return true }),
-		suite.echoContext,
-	).Return(nil)
+		mock.MatchedBy(func(i *models.RestaurantFilter) bool { return true }),
+		models.Ordering(nil),
+		mock.MatchedBy(func(i *models.Pagination) bool { return true }),
+	).Return(nil, errors.New("mocked error"))
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
 
-	suite.echoContext.Echo().Binder = mockBinder
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.List(suite.echoContext)
+	suite.serve(suite.controller.List)
 
 	mockRepo.AssertExpectations(suite.T())
-	mockBinder.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
 }
 
 func (suite *RestaurantControllerTestSuite) TestListPaginateSuccess() {
-	var returnValue []models.Restaurant
 	page := 2
+	pageSize := 5
 
 	mockRepo := &MockRepo{}
 	suite.controller = &RestaurantController{Repo: mockRepo}
 	mockRepo.On(
 		"List",
-		mock.MatchedBy(func(i *models.Restaurant) bool { return true }),
-		"",
-		page,
-	).Return(returnValue, nil)
+		mock.MatchedBy(func(i *models.RestaurantFilter) bool { return true }),
+		models.Ordering(nil),
+		&models.Pagination{Page: page, PageSize: pageSize},
+	).Return(&models.RestaurantPage{Items: []models.Restaurant{}}, nil)
 
-	req := httptest.NewRequest(echo.GET, fmt.Sprintf("/restaurants?page=%d", page), nil)
+	req := httptest.NewRequest(echo.GET, fmt.Sprintf("/restaurants?page=%d&page_size=%d", page, pageSize), nil)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 
-	mockBinder := &MockBinder{}
-	mockBinder.On(
-		"Bind",
-		mock.MatchedBy(func(i interface{}) bool { return true }),
-		suite.echoContext,
-	).Return(nil)
-	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.List(suite.echoContext)
+	suite.serve(suite.controller.List)
 
 	mockRepo.AssertExpectations(suite.T())
-	mockBinder.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
 }
 
 func (suite *RestaurantControllerTestSuite) TestListPaginateFail() {
-	suite.controller = &RestaurantController{}
+	for _, query := range []string{
+		"page=errPage",
+		"page=0",
+		"page_size=0",
+		"page_size=101",
+		"page_size=-1",
+		"page=2&cursor=abc",
+	} {
+		suite.SetupTest()
+		suite.controller = &RestaurantController{}
+
+		req := httptest.NewRequest(echo.GET, "/?"+query, nil)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+		mockValidator := &MockValidator{}
+		mockValidator.On("Validate", mock.Anything).Return(nil)
+		suite.echoContext.Echo().Validator = mockValidator
+
+		suite.serve(suite.controller.List)
+
+		mockValidator.AssertExpectations(suite.T())
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, query)
+	}
+}
 
-	req := httptest.NewRequest(echo.GET, "/?page=errPage", nil)
+func (suite *RestaurantControllerTestSuite) TestListCursorSuccess() {
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo, MaxPageSize: 10}
+	mockRepo.On(
+		"List",
+		mock.MatchedBy(func(i *models.RestaurantFilter) bool { return true }),
+		models.Ordering{{Field: "rating.score", Descending: true}, {Field: "name"}},
+		&models.Pagination{Page: 1, PageSize: 10, Cursor: "abc"},
+	).Return(&models.RestaurantPage{Items: []models.Restaurant{}}, nil)
+
+	req := httptest.NewRequest(echo.GET, "/restaurants?ordering=-rating,name&page_size=10&cursor=abc", nil)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 
-	mockBinder := &MockBinder{}
-	suite.echoContext.Echo().Binder = mockBinder
-	mockBinder.On(
-		"Bind",
-		mock.MatchedBy(func(i interface{}) bool { return true }),
-		suite.echoContext,
-	).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestListOrderingFail() {
+	req := httptest.NewRequest(echo.GET, "/restaurants?ordering=menu,rating,-rating", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	result := &httperrors.Error{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+	suite.Assertions.Equal(result.Details, []httperrors.Detail{
+		{Field: queryOrderParam, Message: fmt.Sprintf(errOrderingFieldMsg, "menu")},
+		{Field: queryOrderParam, Message: fmt.Sprintf(errOrderingRepeatedMsg, "rating")},
+	})
+}
+
+func (suite *RestaurantControllerTestSuite) TestListCursorFail() {
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"List",
+		mock.MatchedBy(func(i *models.RestaurantFilter) bool { return true }),
+		models.Ordering(nil),
+		mock.MatchedBy(func(i *models.Pagination) bool { return true }),
+	).Return(nil, repositories.ErrInvalidCursor)
+
+	req := httptest.NewRequest(echo.GET, "/restaurants?cursor=abc", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestListFilterSuccess() {
+	ratingGte := float32(4.5)
+	hasMenu := true
+	dishPriceLte := 1000
+	filter := &models.RestaurantFilter{
+		City:         "Moscow",
+		CityIn:       []string{"Moscow", "Paris"},
+		NamePrefix:   "Top",
+		RatingGte:    &ratingGte,
+		HasMenu:      &hasMenu,
+		DishPriceLte: &dishPriceLte,
+	}
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"List",
+		filter,
+		models.Ordering(nil),
+		mock.MatchedBy(func(i *models.Pagination) bool { return true }),
+	).Return(&models.RestaurantPage{Items: []models.Restaurant{}}, nil)
+
+	req := httptest.NewRequest(
+		echo.GET,
+		"/restaurants?city=Moscow&city_in=Moscow,&city_in=Paris&name_prefix=Top&rating_gte=4.5&has_menu=true&dish_price_lte=1000",
+		nil,
+	)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", filter).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestListNearSuccess() {
+	radius := 2000.0
+	filter := &models.RestaurantFilter{Near: []float64{37.61, 55.75}, RadiusM: &radius}
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"List",
+		filter,
+		models.Ordering(nil),
+		&models.Pagination{Page: 2, PageSize: defaultPageSize},
+	).Return(&models.RestaurantPage{Items: []models.Restaurant{}}, nil)
+
+	req := httptest.NewRequest(echo.GET, "/restaurants?near=55.75,37.61&radius_m=2000&page=2", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", filter).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestListNearFail() {
+	for _, query := range []string{
+		"near=55.75,37.61",
+		"radius_m=2000",
+		"near=55.75&radius_m=2000",
+		"near=55.75,east&radius_m=2000",
+		"near=55.75,37.61&radius_m=2000&ordering=name",
+		"near=55.75,37.61&radius_m=2000&cursor=abc",
+	} {
+		suite.SetupTest()
+		suite.controller = &RestaurantController{}
+
+		req := httptest.NewRequest(echo.GET, "/restaurants?"+query, nil)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+		mockValidator := &MockValidator{}
+		mockValidator.On("Validate", mock.Anything).Return(nil)
+		suite.echoContext.Echo().Validator = mockValidator
+
+		suite.serve(suite.controller.List)
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, query)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestListFilterFail() {
+	req := httptest.NewRequest(echo.GET, "/restaurants?rating_gte=high&has_menu=maybe&cty=Moscow", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	suite.serve(suite.controller.List)
+
+	result := &httperrors.Error{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+	suite.Assertions.Equal(result.Details, []httperrors.Detail{
+		{Field: "cty", Message: errUnknownParamMsg},
+		{Field: "has_menu", Message: errFilterParamMsg},
+		{Field: "rating_gte", Message: errFilterParamMsg},
+	})
+}
+
+func (suite *RestaurantControllerTestSuite) TestListFailValidate() {
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(errors.New("validate error"))
+
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.List)
+
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestSearchSuccess() {
+	restaurant := &models.Restaurant{ID: bson.ObjectIdHex("5a8ad983591b381c73797521"), Name: "Soup House"}
+	returnValue := &models.SearchPage{
+		Items: []models.SearchResult{{
+			Restaurant: restaurant,
+			Score:      10,
+			Dishes:     []models.DishMatch{{Dish: models.Dish{Name: "Soup", Price: 500}, Highlight: "<em>Soup</em>"}},
+		}},
+		Total:    1,
+		PageSize: 5,
+	}
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Search", "soup", &models.Pagination{Page: 1, PageSize: 5}).Return(returnValue, nil)
+
+	req := httptest.NewRequest(echo.GET, "/restaurants/search?q=+soup+&page_size=5", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	suite.serve(suite.controller.Search)
+
+	result := &models.SearchPage{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(result, returnValue)
+}
+
+func (suite *RestaurantControllerTestSuite) TestSearchFail() {
+	for _, query := range []string{
+		"",
+		"q=++",
+		"q=soup&cursor=abc",
+		"q=soup&page=0",
+	} {
+		suite.SetupTest()
+		suite.controller = &RestaurantController{}
+
+		req := httptest.NewRequest(echo.GET, "/restaurants/search?"+query, nil)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+		suite.serve(suite.controller.Search)
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, query)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestSearchFailService() {
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Search", "soup", mock.Anything).Return(nil, errors.New("mocked error"))
+
+	req := httptest.NewRequest(echo.GET, "/restaurants/search?q=soup", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	suite.serve(suite.controller.Search)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetSuccess() {
+	restaurant := &fixtures.SimpleRestaurantSet()[0]
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues(restaurant.ID.Hex())
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Get",
+		&models.Restaurant{ID: restaurant.ID},
+		false,
+	).Return(restaurant, nil)
+
+	suite.serve(suite.controller.Get)
+
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result, restaurant)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetWithMenuSuccess() {
+	restaurant := &fixtures.SimpleRestaurantSet()[0]
+	restaurant.Menu = []models.Dish{{Name: "Name", Price: 100}}
+
+	req := httptest.NewRequest(echo.GET, "/?menu=true", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues(restaurant.ID.Hex())
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Get",
+		&models.Restaurant{ID: restaurant.ID},
+		true,
+	).Return(restaurant, nil)
+
+	suite.serve(suite.controller.Get)
+
+	result := &models.Menu{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.Menu, restaurant.Menu)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetFailNotFound() {
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Get",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		false,
+	).Return(nil, mgo.ErrNotFound)
+
+	suite.serve(suite.controller.Get)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetFailTimeout() {
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Get",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		false,
+	).Return(nil, context.DeadlineExceeded)
+
+	suite.serve(suite.controller.Get)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusGatewayTimeout)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetFailCanceled() {
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Get",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		false,
+	).Return(nil, context.Canceled)
+
+	suite.serve(suite.controller.Get)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
+}
 
-	suite.controller.List(suite.echoContext)
+func (suite *RestaurantControllerTestSuite) TestGetFailFromObjectIdHex() {
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("bad-object-id")
+
+	suite.controller = &RestaurantController{}
+
+	suite.serve(suite.controller.Get)
 
-	mockBinder.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
 }
 
-func (suite *RestaurantControllerTestSuite) TestListFailBind() {
-	mockBinder := &MockBinder{}
-	mockBinder.On(
-		"Bind",
-		mock.MatchedBy(func(i interface{}) bool { return true }),
-		suite.echoContext,
-	).Return(errors.New("bind error"))
+func (suite *RestaurantControllerTestSuite) TestGetFailFromMenuParam() {
+	req := httptest.NewRequest(echo.GET, "/?menu=maybe", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
-	suite.echoContext.Echo().Binder = mockBinder
+	suite.controller = &RestaurantController{}
 
-	suite.controller.List(suite.echoContext)
+	suite.serve(suite.controller.Get)
 
-	mockBinder.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
 }
 
 func (suite *RestaurantControllerTestSuite) TestCreateSuccess() {
 	body := "body"
-	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(body))
+	req := httptest.NewRequest(echo.POST, "/restaurants", strings.NewReader(body))
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 
+	id := bson.NewObjectId()
 	mockRepo := &MockRepo{}
 	mockBinder := &MockBinder{}
 	suite.controller = &RestaurantController{Repo: mockRepo}
 	mockRepo.On(
 		"Create",
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
-	).Return(nil)
+	).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Restaurant).ID = id
+	}).Return(nil)
 	mockBinder.On(
 		"Bind",
 		mock.MatchedBy(func(i interface{}) bool { return true }),
@@ -222,12 +698,42 @@ func (suite *RestaurantControllerTestSuite) TestCreateSuccess() {
 	).Return(nil)
 
 	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.Create(suite.echoContext)
+	suite.serve(suite.controller.Create)
+
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
 
 	mockBinder.AssertExpectations(suite.T())
 	mockRepo.AssertExpectations(suite.T())
-	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(result.ID, id)
+	suite.Assertions.Equal(suite.recorder.Header().Get(echo.HeaderLocation), "/restaurants/"+id.Hex())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusCreated)
+}
+
+func (suite *RestaurantControllerTestSuite) TestCreateNotInTrash() {
+	body := `{"name": "Name", "city": "Moscow", "deleted_at": "2018-03-01T12:00:00Z"}`
+	req := httptest.NewRequest(echo.POST, "/restaurants", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"Create",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return obj.Name == "Name" && obj.DeletedAt == nil }),
+	).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Create)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusCreated)
 }
 
 func (suite *RestaurantControllerTestSuite) TestCreateFailFromRepo() {
@@ -249,8 +755,11 @@ func (suite *RestaurantControllerTestSuite) TestCreateFailFromRepo() {
 	).Return(nil)
 
 	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.Create(suite.echoContext)
+	suite.serve(suite.controller.Create)
 
 	mockBinder.AssertExpectations(suite.T())
 	mockRepo.AssertExpectations(suite.T())
@@ -272,7 +781,7 @@ func (suite *RestaurantControllerTestSuite) TestCreateFailFromBind() {
 
 	suite.echoContext.Echo().Binder = mockBinder
 
-	suite.controller.Create(suite.echoContext)
+	suite.serve(suite.controller.Create)
 
 	mockBinder.AssertExpectations(suite.T())
 
@@ -280,47 +789,49 @@ func (suite *RestaurantControllerTestSuite) TestCreateFailFromBind() {
 }
 
 func (suite *RestaurantControllerTestSuite) TestUpdateSuccess() {
-	body := "body"
-	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(`{"id": "5a8ad983591b381c73797522", "name": "Name", "city": "City"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	returnValue := &models.Restaurant{ID: id, Name: "Name", City: "City", Rating: &models.Rating{Count: 1, Average: 7, Score: 5.33}}
 	mockRepo := &MockRepo{}
 	mockRepo.On(
 		"Update",
-		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
-		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
-	).Return(nil)
+		&models.Restaurant{ID: id},
+		&models.Restaurant{ID: id, Name: "Name", City: "City"},
+	).Return(returnValue, nil)
 	suite.controller = &RestaurantController{Repo: mockRepo}
-	mockBinder := &MockBinder{}
-	mockBinder.On(
-		"Bind",
-		mock.MatchedBy(func(i interface{}) bool { return true }),
-		suite.echoContext,
-	).Return(nil)
-	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Update)
 
-	suite.controller.Update(suite.echoContext)
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
 
 	mockRepo.AssertExpectations(suite.T())
-	mockBinder.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result, returnValue)
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
 }
 
 func (suite *RestaurantControllerTestSuite) TestUpdateFailNotFound() {
 	body := "body"
-	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(body))
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	mockRepo := &MockRepo{}
 	mockRepo.On(
 		"Update",
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
-	).Return(mgo.ErrNotFound)
+	).Return(nil, mgo.ErrNotFound)
 	suite.controller = &RestaurantController{Repo: mockRepo}
 	mockBinder := &MockBinder{}
 	mockBinder.On(
@@ -329,8 +840,11 @@ func (suite *RestaurantControllerTestSuite) TestUpdateFailNotFound() {
 		suite.echoContext,
 	).Return(nil)
 	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.Update(suite.echoContext)
+	suite.serve(suite.controller.Update)
 
 	mockRepo.AssertExpectations(suite.T())
 	mockBinder.AssertExpectations(suite.T())
@@ -339,10 +853,10 @@ func (suite *RestaurantControllerTestSuite) TestUpdateFailNotFound() {
 
 func (suite *RestaurantControllerTestSuite) TestUpdateFailFromBind() {
 	body := "body"
-	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(body))
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	suite.controller = &RestaurantController{}
 	mockBinder := &MockBinder{}
@@ -353,7 +867,7 @@ func (suite *RestaurantControllerTestSuite) TestUpdateFailFromBind() {
 	).Return(errors.New("bind error"))
 	suite.echoContext.Echo().Binder = mockBinder
 
-	suite.controller.Update(suite.echoContext)
+	suite.serve(suite.controller.Update)
 
 	mockBinder.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
@@ -361,17 +875,17 @@ func (suite *RestaurantControllerTestSuite) TestUpdateFailFromBind() {
 
 func (suite *RestaurantControllerTestSuite) TestUpdateFailFromRepo() {
 	body := "body"
-	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(body))
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	mockRepo := &MockRepo{}
 	mockRepo.On(
 		"Update",
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
-	).Return(errors.New("repo error"))
+	).Return(nil, errors.New("repo error"))
 	suite.controller = &RestaurantController{Repo: mockRepo}
 	mockBinder := &MockBinder{}
 	mockBinder.On(
@@ -380,19 +894,159 @@ func (suite *RestaurantControllerTestSuite) TestUpdateFailFromRepo() {
 		suite.echoContext,
 	).Return(nil)
 	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	suite.controller.Update(suite.echoContext)
+	suite.serve(suite.controller.Update)
 
 	mockRepo.AssertExpectations(suite.T())
 	mockBinder.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
 }
 
+func (suite *RestaurantControllerTestSuite) TestUpdateFailFromValidate() {
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(`{"city": "Gotham"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	suite.controller = &RestaurantController{}
+	mockValidator := &MockValidator{}
+	mockValidator.On(
+		"Validate",
+		&models.Restaurant{ID: bson.ObjectIdHex("5a8ad983591b381c73797521"), City: "Gotham"},
+	).Return(errors.New("validate error"))
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Update)
+
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchSuccess() {
+	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(`{"name": "Patched", "address": null, "location": {"coordinates": [1, 2]}}`))
+	req.Header.Set(echo.HeaderContentType, mergepatch.MIMEMergePatchJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	patched := &models.Restaurant{ID: id, Name: "Patched", City: "City", Location: models.NewGeoPoint(1, 2)}
+	mockRepo := &MockRepo{}
+	mockRepo.On("Get", &models.Restaurant{ID: id}, false).Return(&models.Restaurant{
+		ID:       id,
+		Name:     "Name",
+		City:     "City",
+		Location: models.NewGeoPoint(3, 4),
+		Address:  &models.Address{Street: "Street"},
+	}, nil)
+	mockRepo.On("Update", &models.Restaurant{ID: id}, patched).Return(patched, nil)
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", patched).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Patch)
+
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result, patched)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchFail() {
+	for body, status := range map[string]int{
+		`{"name": `:        http.StatusBadRequest,
+		`["name"]`:         http.StatusBadRequest,
+		`{"name": 5}`:      http.StatusBadRequest,
+		`{"city": "Nope"}`: http.StatusBadRequest,
+	} {
+		suite.SetupTest()
+		req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+		suite.echoContext.SetParamNames("restaurant_id")
+		suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+		mockRepo := &MockRepo{}
+		mockRepo.On("Get", mock.Anything, false).Return(&models.Restaurant{Name: "Name", City: "City"}, nil)
+		suite.controller = &RestaurantController{Repo: mockRepo}
+		mockValidator := &MockValidator{}
+		mockValidator.On("Validate", mock.Anything).Return(errors.New("validate error"))
+		suite.echoContext.Echo().Validator = mockValidator
+
+		suite.serve(suite.controller.Patch)
+
+		mockRepo.AssertExpectations(suite.T())
+		suite.Assertions.Equal(suite.echoContext.Response().Status, status, body)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchFailTrailingData() {
+	for _, body := range []string{`{"name": "a"} garbage`, `{}{"city": "x"}`} {
+		suite.SetupTest()
+		req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, mergepatch.MIMEMergePatchJSON)
+		suite.echoContext = echo.New().NewContext(req, suite.recorder)
+		suite.echoContext.SetParamNames("restaurant_id")
+		suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+		mockRepo := &MockRepo{}
+		mockRepo.On("Get", mock.Anything, false).Return(&models.Restaurant{Name: "Name", City: "City"}, nil)
+		suite.controller = &RestaurantController{Repo: mockRepo}
+		mockValidator := &MockValidator{}
+		mockValidator.On("Validate", mock.Anything).Return(nil)
+		suite.echoContext.Echo().Validator = mockValidator
+
+		suite.serve(suite.controller.Patch)
+
+		mockRepo.AssertNotCalled(suite.T(), "Update", mock.Anything, mock.Anything)
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, body)
+	}
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchFailContentType() {
+	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(`name=Name`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	suite.controller = &RestaurantController{}
+
+	suite.serve(suite.controller.Patch)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusUnsupportedMediaType)
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchFailNotFound() {
+	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(`{"name": "Name"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	mockRepo.On("Get", mock.Anything, false).Return(nil, mgo.ErrNotFound)
+	suite.controller = &RestaurantController{Repo: mockRepo}
+
+	suite.serve(suite.controller.Patch)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
 func (suite *RestaurantControllerTestSuite) TestRemoveSuccess() {
 	req := httptest.NewRequest(echo.DELETE, "/", nil)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	mockRepo := &MockRepo{}
 	mockRepo.On(
@@ -401,7 +1055,7 @@ func (suite *RestaurantControllerTestSuite) TestRemoveSuccess() {
 	).Return(nil)
 	suite.controller = &RestaurantController{Repo: mockRepo}
 
-	suite.controller.Remove(suite.echoContext)
+	suite.serve(suite.controller.Remove)
 
 	mockRepo.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
@@ -411,7 +1065,7 @@ func (suite *RestaurantControllerTestSuite) TestRemoveFailNotFound() {
 	req := httptest.NewRequest(echo.DELETE, "/", nil)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	mockRepo := &MockRepo{}
 	mockRepo.On(
@@ -420,7 +1074,7 @@ func (suite *RestaurantControllerTestSuite) TestRemoveFailNotFound() {
 	).Return(mgo.ErrNotFound)
 	suite.controller = &RestaurantController{Repo: mockRepo}
 
-	suite.controller.Remove(suite.echoContext)
+	suite.serve(suite.controller.Remove)
 
 	mockRepo.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
@@ -430,7 +1084,7 @@ func (suite *RestaurantControllerTestSuite) TestRemoveFailService() {
 	req := httptest.NewRequest(echo.DELETE, "/", nil)
 	suite.echoContext = echo.New().NewContext(req, suite.recorder)
 	suite.echoContext.SetParamNames("restaurant_id")
-	suite.echoContext.SetParamValues("123")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
 	mockRepo := &MockRepo{}
 	mockRepo.On(
@@ -439,19 +1093,91 @@ func (suite *RestaurantControllerTestSuite) TestRemoveFailService() {
 	).Return(errors.New("mocked error"))
 	suite.controller = &RestaurantController{Repo: mockRepo}
 
-	suite.controller.Remove(suite.echoContext)
+	suite.serve(suite.controller.Remove)
 
 	mockRepo.AssertExpectations(suite.T())
 	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusServiceUnavailable)
 }
 
+func (suite *RestaurantControllerTestSuite) TestTrashSuccess() {
+	deletedAt := time.Date(2018, 2, 19, 10, 0, 0, 0, time.UTC)
+	returnValue := &models.RestaurantPage{
+		Items:    []models.Restaurant{{ID: bson.ObjectIdHex("5a8ad983591b381c73797521"), Name: "Name", DeletedAt: &deletedAt}},
+		Total:    1,
+		PageSize: 5,
+	}
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("ListTrash", &models.Pagination{Page: 1, PageSize: 5}).Return(returnValue, nil)
+
+	req := httptest.NewRequest(echo.GET, "/restaurants/trash?page_size=5", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	suite.serve(suite.controller.Trash)
+
+	result := &models.RestaurantPage{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(result, returnValue)
+}
+
+func (suite *RestaurantControllerTestSuite) TestTrashFail() {
+	suite.controller = &RestaurantController{}
+
+	req := httptest.NewRequest(echo.GET, "/restaurants/trash?cursor=abc", nil)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+
+	suite.serve(suite.controller.Trash)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRestoreSuccess() {
+	id := bson.ObjectIdHex("5a8ad983591b381c73797521")
+	suite.echoContext.Request().Header.Set(headerIfMatch, `"3"`)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues(id.Hex())
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Restore", &models.Restaurant{ID: id, Version: 3}).Return(&models.Restaurant{ID: id, Name: "Name", Version: 4}, nil)
+
+	suite.serve(suite.controller.Restore)
+
+	result := &models.Restaurant{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.Name, "Name")
+	suite.Assertions.Equal(suite.recorder.Header().Get(headerETag), `"4"`)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRestoreFailNotFound() {
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On("Restore", mock.Anything).Return(nil, mgo.ErrNotFound)
+
+	suite.serve(suite.controller.Restore)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
 func (suite *RestaurantControllerTestSuite) TestAddDishSuccess() {
 	body := "body"
-	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(body))
-	echoContext := echo.New().NewContext(req, suite.recorder)
-	echoContext.SetParamNames("restaurant_id")
-	echoContext.SetParamValues("5a8ad983591b381c73797521")
+	req := httptest.NewRequest(echo.POST, "/restaurants/5a8ad983591b381c73797521/dish", strings.NewReader(body))
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
 
+	id := bson.NewObjectId()
 	mockRepo := &MockRepo{}
 	mockBinder := &MockBinder{}
 	suite.controller = &RestaurantController{Repo: mockRepo}
@@ -459,38 +1185,203 @@ func (suite *RestaurantControllerTestSuite) TestAddDishSuccess() {
 		"AddDish",
 		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
 		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
-	).Return(nil)
+	).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Dish).ID = id
+	}).Return(nil)
 	mockBinder.On(
 		"Bind",
 		mock.MatchedBy(func(i interface{}) bool { return true }),
-		echoContext,
+		suite.echoContext,
 	).Return(nil)
 
-	echoContext.Echo().Binder = mockBinder
+	suite.echoContext.Echo().Binder = mockBinder
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
 
-	if err := suite.controller.AddDish(echoContext); err != nil {
-		suite.Assertions.Fail(err.Error())
-	}
+	suite.serve(suite.controller.AddDish)
 
 	mockBinder.AssertExpectations(suite.T())
 	mockRepo.AssertExpectations(suite.T())
-	suite.Assertions.Equal(echoContext.Response().Status, http.StatusOK)
+	suite.Assertions.Equal(suite.recorder.Header().Get(echo.HeaderLocation), "/restaurants/5a8ad983591b381c73797521/dish/"+id.Hex())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusCreated)
 }
 
 func (suite *RestaurantControllerTestSuite) TestAddDishFailFromObjectIdHex() {
 	body := "body"
 	req := httptest.NewRequest(echo.POST, "/", strings.NewReader(body))
-	echoContext := echo.New().NewContext(req, suite.recorder)
-	echoContext.SetParamNames("restaurant_id")
-	echoContext.SetParamValues("bad-object-id")
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("bad-object-id")
 
 	suite.controller = &RestaurantController{}
 
-	if err := suite.controller.AddDish(echoContext); err != nil {
-		suite.Assertions.Fail(err.Error())
-	}
+	suite.serve(suite.controller.AddDish)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetDishSuccess() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"GetDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return obj.ID.Hex() == "5a8ad983591b381c73797521" }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return obj.ID.Hex() == "5a8ad983591b381c73797522" }),
+	).Return(nil)
+
+	suite.serve(suite.controller.GetDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetDishFailNotFound() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"GetDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
+	).Return(mgo.ErrNotFound)
+
+	suite.serve(suite.controller.GetDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
+func (suite *RestaurantControllerTestSuite) TestGetDishFailFromObjectIdHex() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "bad-object-id")
+
+	suite.controller = &RestaurantController{}
+
+	suite.serve(suite.controller.GetDish)
 
-	suite.Assertions.Equal(echoContext.Response().Status, http.StatusBadRequest)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestUpdateDishSuccess() {
+	body := `{"id": "5a8ad983591b381c73797599", "name": "Name", "price": 100}`
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"UpdateDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool {
+			return obj.ID.Hex() == "5a8ad983591b381c73797522" && obj.Name == "Name" && obj.Price == 100
+		}),
+	).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.UpdateDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestUpdateDishFailFromValidate() {
+	body := `{"name": "Name"}`
+	req := httptest.NewRequest(echo.PUT, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	suite.controller = &RestaurantController{}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(errors.New("validation error"))
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.UpdateDish)
+
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *RestaurantControllerTestSuite) TestPatchDishSuccess() {
+	body := `{"price": 300}`
+	req := httptest.NewRequest(echo.PATCH, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"GetDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
+	).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Dish).Name = "Stored"
+		args.Get(1).(*models.Dish).Price = 200
+	}).Return(nil)
+	mockRepo.On(
+		"UpdateDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return obj.Name == "Stored" && obj.Price == 300 }),
+	).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.PatchDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRemoveDishSuccess() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"RemoveDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return obj.ID.Hex() == "5a8ad983591b381c73797522" }),
+	).Return(nil)
+
+	suite.serve(suite.controller.RemoveDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *RestaurantControllerTestSuite) TestRemoveDishFailNotFound() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	mockRepo := &MockRepo{}
+	suite.controller = &RestaurantController{Repo: mockRepo}
+	mockRepo.On(
+		"RemoveDish",
+		mock.MatchedBy(func(obj *models.Restaurant) bool { return true }),
+		mock.MatchedBy(func(obj *models.Dish) bool { return true }),
+	).Return(mgo.ErrNotFound)
+
+	suite.serve(suite.controller.RemoveDish)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
 }
 
 func TestRestaurantControllerTestSuite(t *testing.T) {
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+
+	"venues/cmd/models"
+	"venues/cmd/repositories"
+	"venues/cmd/settings"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+)
+
+type ReviewController struct {
+	Repo        repositories.ReviewAccessor
+	MaxPageSize int
+}
+
+// Create adds a review, the rating of the restaurant is recomputed with it
+func (controller *ReviewController) Create(context echo.Context) error {
+	query := restaurantParam(context)
+	review := &models.Review{}
+	if err := context.Bind(review); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	// clients can't review on behalf of someone else
+	review.Author = ""
+	if principal := auth.PrincipalOf(context); principal != nil {
+		review.Author = principal.ID()
+	}
+
+	if err := context.Validate(review); err != nil {
+		return httperrors.BadRequest(err)
+	}
+
+	if err := controller.Repo.Create(context.Request().Context(), query, review); err != nil {
+		return storageError(context, err)
+	}
+
+	return created(context, review.ID, review)
+}
+
+// List renders reviews of the restaurant from the newest one
+func (controller *ReviewController) List(context echo.Context) error {
+	query := restaurantParam(context)
+
+	pagination, err := paginationParams(context, controller.MaxPageSize)
+	if err != nil {
+		return err
+	}
+	if pagination.Cursor != "" {
+		return httperrors.New(http.StatusBadRequest, errReviewCursorMsg)
+	}
+
+	page, err := controller.Repo.List(context.Request().Context(), query, pagination)
+	if err != nil {
+		return storageError(context, err)
+	}
+
+	return context.JSON(http.StatusOK, page)
+}
+
+// NewReviewController sets ratings of restaurants through the accessor
+func NewReviewController(restaurants repositories.RestaurantAccessor) *ReviewController {
+	return &ReviewController{
+		Repo:        repositories.NewReviewRepo(restaurants),
+		MaxPageSize: settings.GetIntSetting("MAX_PAGE_SIZE", defaultMaxPageSize),
+	}
+}
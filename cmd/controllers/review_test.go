@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+	"venues/pkg/httperrors"
+	"venues/pkg/pathparams"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type MockReviewRepo struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepo) Create(ctx context.Context, query *models.Restaurant, object *models.Review) error {
+	args := m.Called(query, object)
+	return args.Error(0)
+}
+
+func (m *MockReviewRepo) List(ctx context.Context, query *models.Restaurant, pagination *models.Pagination) (*models.ReviewPage, error) {
+	args := m.Called(query, pagination)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReviewPage), args.Error(1)
+}
+
+type ReviewControllerTestSuite struct {
+	suite.Suite
+
+	controller  *ReviewController
+	echoContext echo.Context
+	recorder    *httptest.ResponseRecorder
+}
+
+func (suite *ReviewControllerTestSuite) SetupTest() {
+	suite.recorder = httptest.NewRecorder()
+	suite.request(echo.GET, "/restaurants/5a8ad983591b381c73797521/reviews", "")
+}
+
+func (suite *ReviewControllerTestSuite) request(method, target, body string) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+	suite.echoContext.SetParamNames("restaurant_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521")
+}
+
+func (suite *ReviewControllerTestSuite) serve(handler echo.HandlerFunc) {
+	middleware := pathparams.ObjectIDs(restaurantIDParam)
+	if err := middleware(handler)(suite.echoContext); err != nil {
+		httperrors.Handler(err, suite.echoContext)
+	}
+}
+
+func (suite *ReviewControllerTestSuite) TestCreateSuccess() {
+	suite.request(echo.POST, "/restaurants/5a8ad983591b381c73797521/reviews", `{"author": "Bob", "score": 9, "text": "Tasty"}`)
+	auth.SetPrincipal(suite.echoContext, &auth.Principal{Subject: "ann", Method: "jwt"})
+
+	id := bson.NewObjectId()
+	mockRepo := &MockReviewRepo{}
+	suite.controller = &ReviewController{Repo: mockRepo}
+	mockRepo.On(
+		"Create",
+		&models.Restaurant{ID: bson.ObjectIdHex("5a8ad983591b381c73797521")},
+		&models.Review{Author: "jwt:ann", Score: 9, Text: "Tasty"},
+	).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Review).ID = id
+	}).Return(nil)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Create)
+
+	result := &models.Review{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.ID, id)
+	suite.Assertions.Equal(suite.recorder.Header().Get(echo.HeaderLocation), "/restaurants/5a8ad983591b381c73797521/reviews/"+id.Hex())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusCreated)
+}
+
+func (suite *ReviewControllerTestSuite) TestCreateFailValidate() {
+	suite.request(echo.POST, "/restaurants/5a8ad983591b381c73797521/reviews", `{"score": 11}`)
+
+	suite.controller = &ReviewController{}
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(errors.New("mocked error"))
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Create)
+
+	mockValidator.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+}
+
+func (suite *ReviewControllerTestSuite) TestCreateFailNotFound() {
+	suite.request(echo.POST, "/restaurants/5a8ad983591b381c73797521/reviews", `{"author": "Ann", "score": 9}`)
+
+	mockRepo := &MockReviewRepo{}
+	suite.controller = &ReviewController{Repo: mockRepo}
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(mgo.ErrNotFound)
+	mockValidator := &MockValidator{}
+	mockValidator.On("Validate", mock.Anything).Return(nil)
+	suite.echoContext.Echo().Validator = mockValidator
+
+	suite.serve(suite.controller.Create)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
+func (suite *ReviewControllerTestSuite) TestListSuccess() {
+	suite.request(echo.GET, "/restaurants/5a8ad983591b381c73797521/reviews?page=2&page_size=5", "")
+
+	returnValue := &models.ReviewPage{
+		Items:    []models.Review{{ID: bson.NewObjectId(), Author: "Ann", Score: 9}},
+		Total:    6,
+		PageSize: 5,
+	}
+	mockRepo := &MockReviewRepo{}
+	suite.controller = &ReviewController{Repo: mockRepo}
+	mockRepo.On(
+		"List",
+		&models.Restaurant{ID: bson.ObjectIdHex("5a8ad983591b381c73797521")},
+		&models.Pagination{Page: 2, PageSize: 5},
+	).Return(returnValue, nil)
+
+	suite.serve(suite.controller.List)
+
+	result := &models.ReviewPage{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(result.Items[0].Author, "Ann")
+	suite.Assertions.Equal(result.Total, 6)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusOK)
+}
+
+func (suite *ReviewControllerTestSuite) TestListFail() {
+	for _, query := range []string{
+		"page=0",
+		"page_size=101",
+		"cursor=abc",
+	} {
+		suite.SetupTest()
+		suite.request(echo.GET, "/restaurants/5a8ad983591b381c73797521/reviews?"+query, "")
+		suite.controller = &ReviewController{}
+
+		suite.serve(suite.controller.List)
+
+		suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest, query)
+	}
+}
+
+func (suite *ReviewControllerTestSuite) TestListFailNotFound() {
+	mockRepo := &MockReviewRepo{}
+	suite.controller = &ReviewController{Repo: mockRepo}
+	mockRepo.On("List", mock.Anything, mock.Anything).Return(nil, mgo.ErrNotFound)
+
+	suite.serve(suite.controller.List)
+
+	mockRepo.AssertExpectations(suite.T())
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+}
+
+func TestReviewControllerTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewControllerTestSuite))
+}
@@ -11,12 +11,12 @@ func SimpleRestaurantSet() []models.Restaurant {
 		{ID: bson.NewObjectId(),
 			Name:   "Name1",
 			City:   "City1",
-			Rating: 4.5,
+			Rating: &models.Rating{Count: 2, Average: 3, Score: 4.5},
 		},
 		{ID: bson.NewObjectId(),
 			Name:   "Name2",
 			City:   "City2",
-			Rating: 5.5,
+			Rating: &models.Rating{Count: 3, Average: 7, Score: 5.5},
 		},
 	}
 }
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const APIKeyCollectionName = "api_keys"
+
+// APIKey is stored by the hash of the key, the key itself is shown only when it's created,
+// Name is the subject of requests made with the key and Role is their role
+type APIKey struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Name      string        `bson:"name" json:"name"`
+	Role      string        `bson:"role,omitempty" json:"role,omitempty"`
+	Hash      string        `bson:"hash" json:"-"`
+	CreatedAt time.Time     `bson:"created_at" json:"created_at"`
+}
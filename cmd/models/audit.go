@@ -0,0 +1,89 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const AuditEventCollectionName = "audit_events"
+
+// actions recorded by the audit log
+const (
+	AuditCreate      = "restaurant.create"
+	AuditUpdate      = "restaurant.update"
+	AuditRemove      = "restaurant.remove"
+	AuditRestore     = "restaurant.restore"
+	AuditAddOwner    = "restaurant.add_owner"
+	AuditRemoveOwner = "restaurant.remove_owner"
+	AuditRate        = "restaurant.rate"
+	AuditPurge       = "restaurant.purge"
+	AuditAddDish     = "dish.create"
+	AuditUpdateDish  = "dish.update"
+	AuditRemoveDish  = "dish.remove"
+)
+
+// AuditEvent records who changed what, Changes are json fields of the restaurant,
+// or of the dish for dish actions, that differ before and after the change
+type AuditEvent struct {
+	ID           bson.ObjectId     `bson:"_id,omitempty" json:"id,omitempty"`
+	Actor        string            `bson:"actor" json:"actor"`
+	Action       string            `bson:"action" json:"action"`
+	RestaurantID bson.ObjectId     `bson:"restaurant_id" json:"restaurant_id"`
+	DishID       bson.ObjectId     `bson:"dish_id,omitempty" json:"dish_id,omitempty"`
+	Changes      map[string]Change `bson:"changes,omitempty" json:"changes,omitempty"`
+	RequestID    string            `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	CreatedAt    time.Time         `bson:"created_at" json:"created_at"`
+}
+
+// Change of a field, a missing side means the field was added or removed
+type Change struct {
+	Before interface{} `bson:"before,omitempty" json:"before,omitempty"`
+	After  interface{} `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// NewChanges compares json fields of the objects, either of them may be nil
+func NewChanges(before interface{}, after interface{}) map[string]Change {
+	beforeFields, afterFields := jsonFields(before), jsonFields(after)
+
+	changes := map[string]Change{}
+	for field, value := range beforeFields {
+		if other, ok := afterFields[field]; !ok || !reflect.DeepEqual(value, other) {
+			changes[field] = Change{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = Change{After: value}
+		}
+	}
+
+	return changes
+}
+
+func jsonFields(object interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if object == nil || reflect.ValueOf(object).IsNil() {
+		return fields
+	}
+
+	data, _ := json.Marshal(object)
+	json.Unmarshal(data, &fields)
+
+	return fields
+}
+
+// AuditFilter selects events of the restaurant, when it's set, created since the time
+type AuditFilter struct {
+	RestaurantID bson.ObjectId
+	Since        time.Time
+}
+
+type AuditPage struct {
+	Items    []AuditEvent `json:"items"`
+	Total    int          `json:"total"`
+	PageSize int          `json:"page_size"`
+	NextPage int          `json:"next_page,omitempty"`
+}
@@ -4,6 +4,8 @@ import "gopkg.in/mgo.v2/bson"
 
 type Menu struct {
 	Menu []Dish `bson:"menu,omitempty" json:"menu,omitempty"`
+	// Version of the restaurant the menu belongs to
+	Version int `bson:"version,omitempty" json:"-"`
 }
 
 // Price has integer type cause it makes better round control
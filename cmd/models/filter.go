@@ -0,0 +1,17 @@
+package models
+
+// RestaurantFilter is parsed from query params of the listing,
+// json names are the query params, so validation errors point to them.
+// Nil and empty fields don't filter anything
+type RestaurantFilter struct {
+	City         string   `json:"city,omitempty" validate:"omitempty,city"`
+	CityIn       []string `json:"city_in,omitempty" validate:"omitempty,dive,city"`
+	NamePrefix   string   `json:"name_prefix,omitempty" validate:"max=100"`
+	RatingGte    *float32 `json:"rating_gte,omitempty" validate:"omitempty,min=0,max=10"`
+	RatingLte    *float32 `json:"rating_lte,omitempty" validate:"omitempty,min=0,max=10"`
+	HasMenu      *bool    `json:"has_menu,omitempty"`
+	DishPriceLte *int     `json:"dish_price_lte,omitempty" validate:"omitempty,min=0"`
+	// Near is [longitude, latitude], restaurants within RadiusM meters are ordered by distance to it
+	Near    []float64 `json:"near,omitempty" validate:"omitempty,lnglat"`
+	RadiusM *float64  `json:"radius_m,omitempty" validate:"omitempty,gt=0,max=50000"`
+}
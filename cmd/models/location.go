@@ -0,0 +1,18 @@
+package models
+
+// GeoPoint is a GeoJSON point, coordinates are [longitude, latitude]
+type GeoPoint struct {
+	Type        string    `bson:"type" json:"type" validate:"eq=Point"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates" validate:"lnglat"`
+}
+
+func NewGeoPoint(longitude, latitude float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{longitude, latitude}}
+}
+
+type Address struct {
+	Street     string `bson:"street,omitempty" json:"street,omitempty" validate:"max=200"`
+	Building   string `bson:"building,omitempty" json:"building,omitempty" validate:"max=20"`
+	PostalCode string `bson:"postal_code,omitempty" json:"postal_code,omitempty" validate:"max=20"`
+	Country    string `bson:"country,omitempty" json:"country,omitempty" validate:"max=100"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "strings"
+
+// RestaurantOrderings are fields the listing can be ordered by,
+// json names clients send are mapped to stored ones
+var RestaurantOrderings = map[string]string{
+	"id":     "_id",
+	"name":   "name",
+	"city":   "city",
+	"rating": "rating.score",
+}
+
+// OrderingKey is a stored field name with its direction
+type OrderingKey struct {
+	Field      string
+	Descending bool
+}
+
+func (key OrderingKey) String() string {
+	if key.Descending {
+		return "-" + key.Field
+	}
+
+	return key.Field
+}
+
+// Ordering is a list of keys, each next key orders items equal by the previous ones
+type Ordering []OrderingKey
+
+// String is a comma separated list of keys with "-" prefix for descending ones
+func (ordering Ordering) String() string {
+	keys := make([]string, len(ordering))
+	for i, key := range ordering {
+		keys[i] = key.String()
+	}
+
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,19 @@
+package models
+
+// Pagination is what a client asks for,
+// Cursor continues from the previous page and excludes Page
+type Pagination struct {
+	Page     int
+	PageSize int
+	Cursor   string
+}
+
+// NextPage is set for page by page listing,
+// NextCursor is set if ordering supports cursors
+type RestaurantPage struct {
+	Items      []Restaurant `json:"items"`
+	Total      int          `json:"total"`
+	PageSize   int          `json:"page_size"`
+	NextPage   int          `json:"next_page,omitempty"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
@@ -1,13 +1,52 @@
 package models
 
-import "gopkg.in/mgo.v2/bson"
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
 
 const RestaurantCollectionName = "restaurants"
 
 type Restaurant struct {
-	ID     bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
-	Name   string        `bson:"name,omitempty" json:"name,omitempty" validate:"required"`
-	City   string        `bson:"city,omitempty" json:"city,omitempty" query:"city" validate:"required,city"`
-	Rating float32       `bson:"rating,omitempty" json:"rating,omitempty" validate:"isdefault=0,min=0,max=10"`
-	Menu   []Dish        `bson:"menu,omitempty" json:"-"`
+	ID   bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	Name string        `bson:"name,omitempty" json:"name,omitempty" validate:"required"`
+	City string        `bson:"city,omitempty" json:"city,omitempty" validate:"required,city"`
+	Menu []Dish        `bson:"menu,omitempty" json:"-"`
+
+	// Version is incremented by every change, it's the ETag of the restaurant
+	Version int `bson:"version,omitempty" json:"version,omitempty"`
+	// Rating is computed from reviews, it's never written by the restaurant endpoints
+	Rating *Rating `bson:"rating,omitempty" json:"rating,omitempty"`
+	// OwnerIDs are ids of principals allowed to change the restaurant, they are granted by admins
+	OwnerIDs []string `bson:"owner_ids,omitempty" json:"owner_ids,omitempty"`
+	// DeletedAt is set while the restaurant is in the trash
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+
+	Location *GeoPoint `bson:"location,omitempty" json:"location,omitempty"`
+	Address  *Address  `bson:"address,omitempty" json:"address,omitempty"`
+	// Distance in meters is computed for listing near a point, it's never stored
+	Distance *float64 `bson:"-" json:"distance_m,omitempty"`
+}
+
+func (restaurant *Restaurant) IsOwnedBy(ownerID string) bool {
+	for _, id := range restaurant.OwnerIDs {
+		if id == ownerID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Owner is granted to change a restaurant, ID is the id of a principal like "jwt:ann"
+type Owner struct {
+	ID string `json:"owner_id" validate:"required,max=200"`
+}
+
+// RestaurantWithMenu renders a restaurant together with its menu,
+// Restaurant.Menu itself is hidden from json
+type RestaurantWithMenu struct {
+	*Restaurant
+	Menu []Dish `json:"menu"`
 }
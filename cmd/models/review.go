@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const ReviewCollectionName = "reviews"
+
+// RatingPriorMean and RatingPriorWeight make a restaurant with few reviews
+// rated as if it had RatingPriorWeight more reviews scored RatingPriorMean
+const (
+	RatingPriorMean   = 5
+	RatingPriorWeight = 5
+)
+
+// Review scores a restaurant from 1 to 10,
+// the author, the restaurant and timestamps are set by the server, the author is the principal posting it
+type Review struct {
+	ID           bson.ObjectId `bson:"_id,omitempty" json:"id,omitempty"`
+	RestaurantID bson.ObjectId `bson:"restaurant_id,omitempty" json:"restaurant_id,omitempty"`
+	Author       string        `bson:"author,omitempty" json:"author,omitempty" validate:"required"`
+	Score        int           `bson:"score,omitempty" json:"score,omitempty" validate:"required,min=1,max=10"`
+	Text         string        `bson:"text,omitempty" json:"text,omitempty" validate:"max=2000"`
+	CreatedAt    time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// Rating is computed from reviews of a restaurant, clients can't set it.
+// Score is the average pulled towards RatingPriorMean while there are few reviews,
+// so a single review of 10 doesn't put a restaurant on top of the listing
+type Rating struct {
+	Count   int     `bson:"count" json:"count"`
+	Average float32 `bson:"average" json:"average"`
+	Score   float32 `bson:"score" json:"score"`
+}
+
+func NewRating(scores []int) *Rating {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	sum := 0
+	for _, score := range scores {
+		sum += score
+	}
+
+	count := float64(len(scores))
+	return &Rating{
+		Count:   len(scores),
+		Average: roundRating(float64(sum) / count),
+		Score:   roundRating((RatingPriorMean*RatingPriorWeight + float64(sum)) / (RatingPriorWeight + count)),
+	}
+}
+
+// roundRating keeps two decimals, that's enough to compare restaurants
+func roundRating(value float64) float32 {
+	return float32(math.Floor(value*100+0.5) / 100)
+}
+
+// ReviewPage is ordered from the newest review, so it's listed page by page only
+type ReviewPage struct {
+	Items    []Review `json:"items"`
+	Total    int      `json:"total"`
+	PageSize int      `json:"page_size"`
+	NextPage int      `json:"next_page,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// SearchResult is a restaurant found by text search,
+// Dishes are the dishes of its menu matching the search
+type SearchResult struct {
+	*Restaurant
+	Score  float64     `json:"score"`
+	Dishes []DishMatch `json:"dishes"`
+}
+
+// DishMatch is a dish with its name highlighted where it matches the search
+type DishMatch struct {
+	Dish
+	// Highlight is the name of the dish with matched words wrapped in <em> tags
+	Highlight string `json:"highlight"`
+}
+
+// SearchPage is ordered by relevance, so it's listed page by page only
+type SearchPage struct {
+	Items    []SearchResult `json:"items"`
+	Total    int            `json:"total"`
+	PageSize int            `json:"page_size"`
+	NextPage int            `json:"next_page,omitempty"`
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"venues/cmd/models"
+	"venues/cmd/storages"
+	"venues/pkg/auth"
+	"venues/pkg/logging"
+	"venues/pkg/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	_ auth.APIKeyStore = new(APIKeyRepo)
+)
+
+// apiKeyIndex finds keys of requests by their hashes
+var apiKeyIndex = mgo.Index{
+	Key:    []string{"hash"},
+	Unique: true,
+	Name:   "api_keys_hash",
+}
+
+type APIKeyRepo struct {
+	storage mongo.DataAccessor
+}
+
+// Create generates the key and stores its hash, the key is returned to be shown once
+func (repo *APIKeyRepo) Create(ctx context.Context, object *models.APIKey) (string, error) {
+	key, err := auth.NewAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	object.ID = bson.NewObjectId()
+	object.Hash = auth.HashAPIKey(key)
+	object.CreatedAt = time.Now().Truncate(time.Millisecond)
+	if err := repo.storage.Insert(ctx, object); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// List lists keys from the oldest one
+func (repo *APIKeyRepo) List(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := repo.storage.Find(ctx, nil).Sort("created_at", "_id").All(&keys)
+	return keys, err
+}
+
+// Remove revokes the key, requests made with it are not authenticated anymore
+func (repo *APIKeyRepo) Remove(ctx context.Context, id bson.ObjectId) error {
+	return repo.storage.Remove(ctx, bson.M{"_id": id})
+}
+
+func (repo *APIKeyRepo) Lookup(ctx context.Context, hash string) (*auth.Principal, error) {
+	key := &models.APIKey{}
+	err := repo.storage.Find(ctx, bson.M{"hash": hash}).One(key)
+	if err == mgo.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Principal{Subject: key.Name, Role: key.Role}, nil
+}
+
+// EnsureIndexes creates indexes queries of the repo rely on, it's safe to call it again
+func (repo *APIKeyRepo) EnsureIndexes() error {
+	return repo.storage.EnsureIndex(apiKeyIndex)
+}
+
+func NewAPIKeyRepo() *APIKeyRepo {
+	repo := &APIKeyRepo{storage: storages.GetDataAccess(models.APIKeyCollectionName)}
+	if err := repo.EnsureIndexes(); err != nil {
+		logging.Default().WithError(err).Fatalf("Error creating indexes of %s", models.APIKeyCollectionName)
+	}
+
+	return repo
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"testing"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+	"venues/pkg/mongo"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type APIKeyRepoTestSuite struct {
+	suite.Suite
+
+	repo *APIKeyRepo
+}
+
+func (suite *APIKeyRepoTestSuite) SetupTest() {
+	suite.repo = &APIKeyRepo{storage: mongo.NewMemoryDataAccess()}
+	if err := suite.repo.EnsureIndexes(); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+}
+
+func (suite *APIKeyRepoTestSuite) TestCreateAndLookup() {
+	object := &models.APIKey{Name: "deploy", Role: auth.RoleOwner}
+	key, err := suite.repo.Create(ctx, object)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(object.ID.Valid())
+	suite.Assertions.Equal(object.Hash, auth.HashAPIKey(key))
+	suite.Assertions.NotContains(object.Hash, key)
+
+	principal, err := suite.repo.Lookup(ctx, auth.HashAPIKey(key))
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(principal, &auth.Principal{Subject: "deploy", Role: auth.RoleOwner})
+
+	principal, err = suite.repo.Lookup(ctx, auth.HashAPIKey("wrong"))
+	suite.Assertions.Nil(err)
+	suite.Assertions.Nil(principal)
+}
+
+func (suite *APIKeyRepoTestSuite) TestListAndRemove() {
+	first, second := &models.APIKey{Name: "first"}, &models.APIKey{Name: "second"}
+	suite.repo.Create(ctx, first)
+	key, _ := suite.repo.Create(ctx, second)
+
+	keys, err := suite.repo.List(ctx)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(keys, 2)
+	suite.Assertions.Equal(keys[0].Name, "first")
+
+	suite.Assertions.Nil(suite.repo.Remove(ctx, second.ID))
+	suite.Assertions.Equal(suite.repo.Remove(ctx, bson.NewObjectId()), mgo.ErrNotFound)
+
+	principal, _ := suite.repo.Lookup(ctx, auth.HashAPIKey(key))
+	suite.Assertions.Nil(principal)
+	keys, _ = suite.repo.List(ctx)
+	suite.Assertions.Len(keys, 1)
+}
+
+func TestAPIKeyRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyRepoTestSuite))
+}
@@ -0,0 +1,285 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"venues/cmd/models"
+	"venues/cmd/storages"
+	"venues/pkg/auth"
+	"venues/pkg/logging"
+	"venues/pkg/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	_ AuditAccessor      = new(AuditRepo)
+	_ RestaurantAccessor = new(AuditedRestaurantRepo)
+)
+
+// auditIndexes list events of a restaurant or of all of them in the order they happened
+var auditIndexes = []mgo.Index{
+	{Key: []string{"restaurant_id", "created_at", "_id"}, Name: "audit_restaurant"},
+	{Key: []string{"created_at", "_id"}, Name: "audit_created"},
+}
+
+type AuditAccessor interface {
+	Create(context.Context, *models.AuditEvent) error
+	List(context.Context, *models.AuditFilter, *models.Pagination) (*models.AuditPage, error)
+}
+
+type AuditRepo struct {
+	storage mongo.DataAccessor
+}
+
+// the id and the time of the event are set here
+func (repo *AuditRepo) Create(ctx context.Context, object *models.AuditEvent) error {
+	object.ID = bson.NewObjectId()
+	object.CreatedAt = time.Now().Truncate(time.Millisecond)
+	return repo.storage.Insert(ctx, object)
+}
+
+// List lists events page by page in the order they happened
+func (repo *AuditRepo) List(ctx context.Context, filter *models.AuditFilter, pagination *models.Pagination) (*models.AuditPage, error) {
+	find := bson.M{}
+	if filter.RestaurantID != "" {
+		find["restaurant_id"] = filter.RestaurantID
+	}
+	if !filter.Since.IsZero() {
+		find["created_at"] = bson.M{"$gte": filter.Since}
+	}
+
+	total, err := repo.storage.Find(ctx, find).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber := pagination.Page
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	events := []models.AuditEvent{}
+	err = repo.storage.Find(ctx, find).
+		Sort("created_at", "_id").
+		Skip(pagination.PageSize * (pageNumber - 1)).
+		Limit(pagination.PageSize + 1).
+		All(&events)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuditPage{Items: events, Total: total, PageSize: pagination.PageSize}
+	if len(events) > pagination.PageSize {
+		page.Items = events[:pagination.PageSize]
+		page.NextPage = pageNumber + 1
+	}
+
+	return page, nil
+}
+
+// EnsureIndexes creates indexes queries of the repo rely on, it's safe to call it again
+func (repo *AuditRepo) EnsureIndexes() error {
+	for _, index := range auditIndexes {
+		if err := repo.storage.EnsureIndex(index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func NewAuditRepo() *AuditRepo {
+	repo := &AuditRepo{storage: storages.GetDataAccess(models.AuditEventCollectionName)}
+	if err := repo.EnsureIndexes(); err != nil {
+		logging.Default().WithError(err).Fatalf("Error creating indexes of %s", models.AuditEventCollectionName)
+	}
+
+	return repo
+}
+
+// AuditedRestaurantRepo records every successful change made through the wrapped accessor
+// on behalf of the principal and the request the context carries
+type AuditedRestaurantRepo struct {
+	RestaurantAccessor
+
+	events AuditAccessor
+}
+
+func NewAuditedRestaurantRepo(repo RestaurantAccessor, events AuditAccessor) *AuditedRestaurantRepo {
+	return &AuditedRestaurantRepo{RestaurantAccessor: repo, events: events}
+}
+
+// detachedContext keeps values of the context, like its span, without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// record doesn't fail the change, which is already made, when the event can't be stored,
+// the event is stored even if the request is cancelled right after the change
+func (repo *AuditedRestaurantRepo) record(ctx context.Context, action string, restaurantID bson.ObjectId, dishID bson.ObjectId, changes map[string]models.Change) {
+	event := &models.AuditEvent{
+		Action:       action,
+		RestaurantID: restaurantID,
+		DishID:       dishID,
+		Changes:      changes,
+		RequestID:    logging.RequestIDFromContext(ctx),
+	}
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		event.Actor = principal.ID()
+	}
+	if err := repo.events.Create(detachedContext{ctx}, event); err != nil {
+		logging.FromContext(ctx).WithError(err).With("restaurant_id", restaurantID.Hex()).Errorf("Error recording %s", action)
+	}
+}
+
+// before reads the restaurant to compare it with the changed one, nil when it's not found
+func (repo *AuditedRestaurantRepo) before(ctx context.Context, query *models.Restaurant) *models.Restaurant {
+	restaurant, err := repo.RestaurantAccessor.Get(ctx, &models.Restaurant{ID: query.ID}, false)
+	if err != nil {
+		return nil
+	}
+
+	return restaurant
+}
+
+func (repo *AuditedRestaurantRepo) beforeDish(ctx context.Context, query *models.Restaurant, object *models.Dish) *models.Dish {
+	dish := &models.Dish{ID: object.ID}
+	if err := repo.RestaurantAccessor.GetDish(ctx, &models.Restaurant{ID: query.ID}, dish); err != nil {
+		return nil
+	}
+
+	return dish
+}
+
+func (repo *AuditedRestaurantRepo) Create(ctx context.Context, object *models.Restaurant) error {
+	if err := repo.RestaurantAccessor.Create(ctx, object); err != nil {
+		return err
+	}
+
+	repo.record(ctx, models.AuditCreate, object.ID, "", models.NewChanges(nil, object))
+	return nil
+}
+
+func (repo *AuditedRestaurantRepo) Update(ctx context.Context, query *models.Restaurant, object *models.Restaurant) (*models.Restaurant, error) {
+	before := repo.before(ctx, query)
+	result, err := repo.RestaurantAccessor.Update(ctx, query, object)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.record(ctx, models.AuditUpdate, query.ID, "", models.NewChanges(before, result))
+	return result, nil
+}
+
+func (repo *AuditedRestaurantRepo) Remove(ctx context.Context, query *models.Restaurant) error {
+	if err := repo.RestaurantAccessor.Remove(ctx, query); err != nil {
+		return err
+	}
+
+	repo.record(ctx, models.AuditRemove, query.ID, "", nil)
+	return nil
+}
+
+func (repo *AuditedRestaurantRepo) Restore(ctx context.Context, query *models.Restaurant) (*models.Restaurant, error) {
+	result, err := repo.RestaurantAccessor.Restore(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.record(ctx, models.AuditRestore, query.ID, "", nil)
+	return result, nil
+}
+
+func (repo *AuditedRestaurantRepo) AddOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	before := repo.before(ctx, query)
+	result, err := repo.RestaurantAccessor.AddOwner(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.record(ctx, models.AuditAddOwner, query.ID, "", models.NewChanges(before, result))
+	return result, nil
+}
+
+func (repo *AuditedRestaurantRepo) RemoveOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	before := repo.before(ctx, query)
+	result, err := repo.RestaurantAccessor.RemoveOwner(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo.record(ctx, models.AuditRemoveOwner, query.ID, "", models.NewChanges(before, result))
+	return result, nil
+}
+
+// SetRating records the rating as a change of the restaurant, it's compared with the one read right before
+func (repo *AuditedRestaurantRepo) SetRating(ctx context.Context, query *models.Restaurant, rating *models.Rating) error {
+	before := repo.before(ctx, query)
+	if err := repo.RestaurantAccessor.SetRating(ctx, query, rating); err != nil {
+		return err
+	}
+
+	var changes map[string]models.Change
+	if before != nil {
+		after := *before
+		after.Rating = rating
+		after.Version++
+		changes = models.NewChanges(before, &after)
+	}
+	repo.record(ctx, models.AuditRate, query.ID, "", changes)
+	return nil
+}
+
+// Purge records every purged restaurant, the ones purged before an error as well
+func (repo *AuditedRestaurantRepo) Purge(ctx context.Context, before time.Time) ([]bson.ObjectId, error) {
+	ids, err := repo.RestaurantAccessor.Purge(ctx, before)
+	for _, id := range ids {
+		repo.record(ctx, models.AuditPurge, id, "", nil)
+	}
+
+	return ids, err
+}
+
+func (repo *AuditedRestaurantRepo) AddDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	if err := repo.RestaurantAccessor.AddDish(ctx, query, object); err != nil {
+		return err
+	}
+
+	repo.record(ctx, models.AuditAddDish, query.ID, object.ID, models.NewChanges(nil, object))
+	return nil
+}
+
+func (repo *AuditedRestaurantRepo) UpdateDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	before := repo.beforeDish(ctx, query, object)
+	if err := repo.RestaurantAccessor.UpdateDish(ctx, query, object); err != nil {
+		return err
+	}
+
+	repo.record(ctx, models.AuditUpdateDish, query.ID, object.ID, models.NewChanges(before, object))
+	return nil
+}
+
+func (repo *AuditedRestaurantRepo) RemoveDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	before := repo.beforeDish(ctx, query, object)
+	if err := repo.RestaurantAccessor.RemoveDish(ctx, query, object); err != nil {
+		return err
+	}
+
+	repo.record(ctx, models.AuditRemoveDish, query.ID, object.ID, models.NewChanges(before, nil))
+	return nil
+}
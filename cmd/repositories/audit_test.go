@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"venues/cmd/models"
+	"venues/pkg/auth"
+	"venues/pkg/logging"
+	"venues/pkg/mongo"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type AuditRepoTestSuite struct {
+	suite.Suite
+
+	storage *mongo.MemoryDataAccess
+	events  *AuditRepo
+	repo    *AuditedRestaurantRepo
+	// ctx carries the principal and the request the changes are made by
+	ctx context.Context
+}
+
+func (suite *AuditRepoTestSuite) SetupTest() {
+	suite.storage = mongo.NewMemoryDataAccess()
+	suite.events = &AuditRepo{storage: suite.storage}
+	if err := suite.events.EnsureIndexes(); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	restaurants := &RestaurantRepo{storage: mongo.NewMemoryDataAccess()}
+	suite.repo = NewAuditedRestaurantRepo(restaurants, suite.events)
+	suite.ctx = logging.WithRequestID(auth.WithPrincipal(ctx, &auth.Principal{Subject: "deploy", Method: "api_key"}), "request")
+}
+
+func (suite *AuditRepoTestSuite) list(filter *models.AuditFilter) []models.AuditEvent {
+	page, err := suite.events.List(ctx, filter, firstPage())
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	return page.Items
+}
+
+func (suite *AuditRepoTestSuite) TestRestaurantChanges() {
+	object := &models.Restaurant{Name: "Name", City: "Moscow"}
+	suite.Assertions.Nil(suite.repo.Create(suite.ctx, object))
+	_, err := suite.repo.Update(suite.ctx, &models.Restaurant{ID: object.ID}, &models.Restaurant{Name: "Updated", City: "Moscow"})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Nil(suite.repo.Remove(suite.ctx, &models.Restaurant{ID: object.ID}))
+	_, err = suite.repo.Restore(suite.ctx, &models.Restaurant{ID: object.ID})
+	suite.Assertions.Nil(err)
+
+	events := suite.list(&models.AuditFilter{RestaurantID: object.ID})
+	suite.Assertions.Len(events, 4)
+	for i, action := range []string{models.AuditCreate, models.AuditUpdate, models.AuditRemove, models.AuditRestore} {
+		suite.Assertions.Equal(events[i].Action, action)
+		suite.Assertions.Equal(events[i].Actor, "api_key:deploy")
+		suite.Assertions.Equal(events[i].RequestID, "request")
+		suite.Assertions.Equal(events[i].RestaurantID, object.ID)
+	}
+
+	suite.Assertions.Equal(events[0].Changes["name"], models.Change{After: "Name"})
+	suite.Assertions.Equal(events[1].Changes["name"], models.Change{Before: "Name", After: "Updated"})
+	suite.Assertions.NotContains(events[1].Changes, "city")
+}
+
+func (suite *AuditRepoTestSuite) TestDishChanges() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(suite.ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	dish := &models.Dish{Name: "Soup", Price: 500}
+	suite.Assertions.Nil(suite.repo.AddDish(suite.ctx, query, dish))
+	suite.Assertions.Nil(suite.repo.UpdateDish(suite.ctx, query, &models.Dish{ID: dish.ID, Name: "Soup", Price: 450}))
+	suite.Assertions.Nil(suite.repo.RemoveDish(suite.ctx, query, &models.Dish{ID: dish.ID}))
+
+	events := suite.list(&models.AuditFilter{RestaurantID: object.ID})
+	suite.Assertions.Len(events, 4)
+	suite.Assertions.Equal(events[1].Action, models.AuditAddDish)
+	suite.Assertions.Equal(events[1].DishID, dish.ID)
+	suite.Assertions.Equal(events[2].Changes, map[string]models.Change{"price": {Before: float64(500), After: float64(450)}})
+	suite.Assertions.Equal(events[3].Changes["name"], models.Change{Before: "Soup"})
+}
+
+func (suite *AuditRepoTestSuite) TestOwnerChanges() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(suite.ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	_, err := suite.repo.AddOwner(suite.ctx, query, "jwt:ann")
+	suite.Assertions.Nil(err)
+	_, err = suite.repo.RemoveOwner(suite.ctx, query, "jwt:ann")
+	suite.Assertions.Nil(err)
+
+	events := suite.list(&models.AuditFilter{RestaurantID: object.ID})
+	suite.Assertions.Len(events, 3)
+	suite.Assertions.Equal(events[1].Action, models.AuditAddOwner)
+	suite.Assertions.Equal(events[1].Changes["owner_ids"], models.Change{After: []interface{}{"jwt:ann"}})
+	suite.Assertions.Equal(events[2].Action, models.AuditRemoveOwner)
+}
+
+func (suite *AuditRepoTestSuite) TestRating() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(suite.ctx, object)
+	restaurants := suite.repo.RestaurantAccessor.(*RestaurantRepo).storage
+	reviews := &ReviewRepo{storage: mongo.NewMemoryDataAccess(), restaurants: restaurants, ratings: suite.repo}
+
+	suite.Assertions.Nil(reviews.Create(suite.ctx, &models.Restaurant{ID: object.ID}, &models.Review{Author: "Ann", Score: 8}))
+
+	events := suite.list(&models.AuditFilter{RestaurantID: object.ID})
+	suite.Assertions.Len(events, 2)
+	suite.Assertions.Equal(events[1].Action, models.AuditRate)
+	suite.Assertions.Equal(events[1].Actor, "api_key:deploy")
+	suite.Assertions.Contains(events[1].Changes, "rating")
+	suite.Assertions.Nil(events[1].Changes["rating"].Before)
+	suite.Assertions.NotContains(events[1].Changes, "name")
+}
+
+func (suite *AuditRepoTestSuite) TestPurge() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(suite.ctx, object)
+	suite.repo.Remove(suite.ctx, &models.Restaurant{ID: object.ID})
+	purgeCtx := auth.WithPrincipal(ctx, &auth.Principal{Subject: "purge", Method: "system"})
+
+	ids, err := suite.repo.Purge(purgeCtx, time.Now().Add(time.Second))
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(ids, []bson.ObjectId{object.ID})
+	events := suite.list(&models.AuditFilter{RestaurantID: object.ID})
+	suite.Assertions.Len(events, 3)
+	suite.Assertions.Equal(events[2].Action, models.AuditPurge)
+	suite.Assertions.Equal(events[2].Actor, "system:purge")
+}
+
+func (suite *AuditRepoTestSuite) TestFailedChangeNotRecorded() {
+	err := suite.repo.Remove(suite.ctx, &models.Restaurant{ID: bson.NewObjectId()})
+
+	suite.Assertions.Error(err)
+	suite.Assertions.Empty(suite.list(&models.AuditFilter{}))
+}
+
+func (suite *AuditRepoTestSuite) TestRecordFailKeepsChange() {
+	mockAccess := &MockDataAccess{}
+	mockAccess.On("Insert", mock.Anything).Return(errors.New("mocked error"))
+	restaurants := &RestaurantRepo{storage: mongo.NewMemoryDataAccess()}
+	suite.repo = NewAuditedRestaurantRepo(restaurants, &AuditRepo{storage: mockAccess})
+
+	object := &models.Restaurant{Name: "Name"}
+	err := suite.repo.Create(suite.ctx, object)
+
+	mockAccess.AssertExpectations(suite.T())
+	suite.Assertions.Nil(err)
+	_, err = restaurants.Get(ctx, &models.Restaurant{ID: object.ID}, false)
+	suite.Assertions.Nil(err)
+}
+
+func (suite *AuditRepoTestSuite) TestList() {
+	first, second := bson.NewObjectId(), bson.NewObjectId()
+	suite.events.Create(ctx, &models.AuditEvent{Action: models.AuditCreate, RestaurantID: first})
+	time.Sleep(time.Millisecond)
+	since := time.Now()
+	for _, id := range []bson.ObjectId{second, first, first} {
+		suite.events.Create(ctx, &models.AuditEvent{Action: models.AuditUpdate, RestaurantID: id})
+	}
+
+	suite.Assertions.Len(suite.list(&models.AuditFilter{}), 4)
+	suite.Assertions.Len(suite.list(&models.AuditFilter{RestaurantID: first}), 3)
+	suite.Assertions.Len(suite.list(&models.AuditFilter{RestaurantID: first, Since: since}), 2)
+
+	page, err := suite.events.List(ctx, &models.AuditFilter{Since: since}, &models.Pagination{Page: 1, PageSize: 2})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(page.Total, 3)
+	suite.Assertions.Equal(page.NextPage, 2)
+	suite.Assertions.Equal(page.Items[0].RestaurantID, second)
+}
+
+func TestAuditRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditRepoTestSuite))
+}
@@ -1,5 +0,0 @@
-package repositories
-
-const (
-	pageSize = 20
-)
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"regexp"
+
+	"venues/cmd/models"
+	"venues/pkg/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// locationIndex is required by $nearSphere
+var locationIndex = mgo.Index{
+	Key:  []string{"$2dsphere:location"},
+	Name: "restaurants_location",
+}
+
+// filterQuery translates the filter into a mongo query,
+// every set field adds a condition and all of them have to match
+func filterQuery(filter *models.RestaurantFilter) bson.M {
+	conditions := filterConditions(filter)
+	if filter.Near != nil {
+		conditions = append(conditions, bson.M{"location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": []interface{}{filter.Near, radius(filter) / mongo.EarthRadius},
+		}}})
+	}
+
+	return allOf(conditions)
+}
+
+// nearQuery is filterQuery ordering restaurants by distance to the point of the filter,
+// unlike $geoWithin, $nearSphere can't be used to count them
+func nearQuery(filter *models.RestaurantFilter) bson.M {
+	conditions := append(filterConditions(filter), bson.M{"location": bson.M{"$nearSphere": bson.M{
+		"$geometry":    models.NewGeoPoint(filter.Near[0], filter.Near[1]),
+		"$maxDistance": radius(filter),
+	}}})
+
+	return allOf(conditions)
+}
+
+func radius(filter *models.RestaurantFilter) float64 {
+	if filter.RadiusM == nil {
+		return 0
+	}
+
+	return *filter.RadiusM
+}
+
+func filterConditions(filter *models.RestaurantFilter) []bson.M {
+	// restaurants in the trash are listed only by ListTrash
+	conditions := []bson.M{{"deleted_at": bson.M{"$exists": false}}}
+
+	if filter.City != "" {
+		conditions = append(conditions, bson.M{"city": filter.City})
+	}
+
+	if len(filter.CityIn) > 0 {
+		conditions = append(conditions, bson.M{"city": bson.M{"$in": filter.CityIn}})
+	}
+
+	// anchored case sensitive prefix is able to use an index on name
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, bson.M{"name": bson.RegEx{Pattern: "^" + regexp.QuoteMeta(filter.NamePrefix)}})
+	}
+
+	// restaurants without reviews have no rating, they have the lowest one
+	if filter.RatingGte != nil && *filter.RatingGte > 0 {
+		conditions = append(conditions, bson.M{"rating.score": bson.M{"$gte": *filter.RatingGte}})
+	}
+
+	if filter.RatingLte != nil {
+		conditions = append(conditions, bson.M{"$or": []bson.M{
+			{"rating.score": bson.M{"$lte": *filter.RatingLte}},
+			{"rating.score": bson.M{"$exists": false}},
+		}})
+	}
+
+	if filter.HasMenu != nil {
+		conditions = append(conditions, bson.M{"menu.0": bson.M{"$exists": *filter.HasMenu}})
+	}
+
+	if filter.DishPriceLte != nil {
+		conditions = append(conditions, bson.M{"menu.price": bson.M{"$lte": *filter.DishPriceLte}})
+	}
+
+	return conditions
+}
+
+func allOf(conditions []bson.M) bson.M {
+	switch len(conditions) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return conditions[0]
+	}
+
+	return bson.M{"$and": conditions}
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"venues/cmd/models"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid or doesn't match the ordering")
+
+// cursor points to the last restaurant of a page,
+// the next page starts right after it.
+// It's bson, so values are compared with stored ones of the same type
+type cursor struct {
+	Ordering string        `bson:"o"`
+	Values   []interface{} `bson:"v"`
+}
+
+// keyset is an ordering that ends with _id, so no two restaurants are equal by it,
+// it lets to continue from a cursor with an indexed query instead of skip
+type keyset struct {
+	keys models.Ordering
+}
+
+// newKeyset appends _id to the ordering with the direction of the last key
+func newKeyset(ordering models.Ordering) *keyset {
+	for _, key := range ordering {
+		if key.Field == "_id" {
+			return &keyset{keys: ordering}
+		}
+	}
+
+	tiebreak := models.OrderingKey{Field: "_id"}
+	if len(ordering) > 0 {
+		tiebreak.Descending = ordering[len(ordering)-1].Descending
+	}
+
+	keys := append(models.Ordering{}, ordering...)
+	return &keyset{keys: append(keys, tiebreak)}
+}
+
+func (k *keyset) sort() []string {
+	fields := make([]string, len(k.keys))
+	for i, key := range k.keys {
+		fields[i] = key.String()
+	}
+
+	return fields
+}
+
+func (k *keyset) encodeCursor(restaurant *models.Restaurant) string {
+	document := bson.M{}
+	data, _ := bson.Marshal(restaurant)
+	bson.Unmarshal(data, document)
+
+	values := make([]interface{}, len(k.keys))
+	for i, key := range k.keys {
+		values[i] = fieldValue(document, key.Field)
+	}
+
+	data, _ = bson.Marshal(&cursor{Ordering: k.keys.String(), Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k *keyset) decodeCursor(value string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	result := &cursor{}
+	if err := bson.Unmarshal(data, result); err != nil || result.Ordering != k.keys.String() || len(result.Values) != len(k.keys) {
+		return nil, ErrInvalidCursor
+	}
+
+	return result, nil
+}
+
+// after matches restaurants following the cursor in the keyset ordering:
+// equal by the first keys and beyond the cursor by the next one
+func (k *keyset) after(c *cursor) bson.M {
+	var branches []bson.M
+	for i, key := range k.keys {
+		beyond := beyondValue(key, c.Values[i])
+		if beyond == nil {
+			continue
+		}
+
+		conditions := []bson.M{beyond}
+		for j, previous := range k.keys[:i] {
+			conditions = append(conditions, equalValue(previous, c.Values[j]))
+		}
+		branches = append(branches, bson.M{"$and": conditions})
+	}
+
+	return bson.M{"$or": branches}
+}
+
+// fieldValue looks up dotted fields like "rating.score", missing ones are nil
+func fieldValue(document bson.M, field string) interface{} {
+	var value interface{} = document
+	for _, key := range strings.Split(field, ".") {
+		embedded, ok := value.(bson.M)
+		if !ok {
+			return nil
+		}
+		value = embedded[key]
+	}
+
+	return value
+}
+
+// empty values are not stored, such restaurants go first in ascending ordering
+func equalValue(key models.OrderingKey, value interface{}) bson.M {
+	if value == nil {
+		return bson.M{key.Field: bson.M{"$exists": false}}
+	}
+
+	return bson.M{key.Field: value}
+}
+
+// beyondValue is nil when nothing follows the value
+func beyondValue(key models.OrderingKey, value interface{}) bson.M {
+	switch {
+	case value == nil && key.Descending:
+		return nil
+	case value == nil:
+		return bson.M{key.Field: bson.M{"$exists": true}}
+	case key.Descending:
+		return bson.M{"$or": []bson.M{
+			{key.Field: bson.M{"$lt": value}},
+			{key.Field: bson.M{"$exists": false}},
+		}}
+	}
+
+	return bson.M{key.Field: bson.M{"$gt": value}}
+}
@@ -1,11 +1,17 @@
 package repositories
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"venues/cmd/models"
+	"venues/pkg/logging"
 	"venues/pkg/mongo"
 
 	"venues/cmd/storages"
 
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -13,59 +19,397 @@ var (
 	_ RestaurantAccessor = new(RestaurantRepo)
 )
 
+// ErrVersionConflict means the restaurant was changed since the version of the query
+var ErrVersionConflict = errors.New("restaurant was changed, its version doesn't match")
+
+// RestaurantAccessor methods changing a restaurant increment its version,
+// a query with non-zero Version matches only that version of the restaurant.
+// Removed restaurants stay in the trash until they are restored or purged,
+// methods other than ListTrash and Restore don't find them.
 type RestaurantAccessor interface {
-	Create(*models.Restaurant) error
-	List(*models.Restaurant, string, int) ([]models.Restaurant, error)
-	Update(*models.Restaurant, *models.Restaurant) error
-	Remove(*models.Restaurant) error
-	AddDish(*models.Restaurant, *models.Dish) error
-	ListDish(*models.Restaurant, *models.Menu) error
+	Create(context.Context, *models.Restaurant) error
+	Get(context.Context, *models.Restaurant, bool) (*models.Restaurant, error)
+	List(context.Context, *models.RestaurantFilter, models.Ordering, *models.Pagination) (*models.RestaurantPage, error)
+	Search(context.Context, string, *models.Pagination) (*models.SearchPage, error)
+	Update(context.Context, *models.Restaurant, *models.Restaurant) (*models.Restaurant, error)
+	Remove(context.Context, *models.Restaurant) error
+	AddDish(context.Context, *models.Restaurant, *models.Dish) error
+	ListDish(context.Context, *models.Restaurant, *models.Menu) error
+	GetDish(context.Context, *models.Restaurant, *models.Dish) error
+	UpdateDish(context.Context, *models.Restaurant, *models.Dish) error
+	RemoveDish(context.Context, *models.Restaurant, *models.Dish) error
+	ListTrash(context.Context, *models.Pagination) (*models.RestaurantPage, error)
+	Restore(context.Context, *models.Restaurant) (*models.Restaurant, error)
+	AddOwner(context.Context, *models.Restaurant, string) (*models.Restaurant, error)
+	RemoveOwner(context.Context, *models.Restaurant, string) (*models.Restaurant, error)
+	SetRating(context.Context, *models.Restaurant, *models.Rating) error
+	Purge(context.Context, time.Time) ([]bson.ObjectId, error)
 }
 
+// writableFields are stored fields of a restaurant that clients set,
+// the menu is changed by dish methods, owners by owner methods and the rating by ReviewRepo
+var writableFields = []string{"name", "city", "location", "address"}
+
 type RestaurantRepo struct {
 	storage mongo.DataAccessor
 }
 
-func (repo *RestaurantRepo) List(filter *models.Restaurant, ordering string, page int) ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
+// List fetches one more restaurant than the page size to know if there is a next page,
+// the ordering always ends with _id, so pages don't overlap and cursors are available
+func (repo *RestaurantRepo) List(ctx context.Context, filter *models.RestaurantFilter, ordering models.Ordering, pagination *models.Pagination) (*models.RestaurantPage, error) {
+	query := filterQuery(filter)
+
+	total, err := repo.storage.Find(ctx, query).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := newKeyset(ordering)
+
+	pageNumber := pagination.Page
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	if filter.Near != nil {
+		return repo.listNear(ctx, filter, pagination, pageNumber, total)
+	}
+
+	var find mongo.Querier
+	switch {
+	case pagination.Cursor != "":
+		after, err := keys.decodeCursor(pagination.Cursor)
+		if err != nil {
+			return nil, err
+		}
 
-	query := repo.storage.Find(filter).Select(bson.M{"menu": 0})
-	if ordering != "" {
-		query = query.Sort(ordering)
+		find = repo.storage.Find(ctx, bson.M{"$and": []bson.M{query, keys.after(after)}})
+	default:
+		find = repo.storage.Find(ctx, query).Skip(pagination.PageSize * (pageNumber - 1))
 	}
 
-	if page != 0 {
-		query = query.Skip(pageSize * (page - 1)).Limit(pageSize)
+	restaurants := []models.Restaurant{}
+	err = find.Sort(keys.sort()...).Select(bson.M{"menu": 0}).Limit(pagination.PageSize + 1).All(&restaurants)
+	if err != nil {
+		return nil, err
 	}
 
-	err := query.All(&restaurants)
+	page := &models.RestaurantPage{Items: restaurants, Total: total, PageSize: pagination.PageSize}
+	if len(restaurants) > pagination.PageSize {
+		page.Items = restaurants[:pagination.PageSize]
+
+		if pagination.Cursor == "" {
+			page.NextPage = pageNumber + 1
+		}
+
+		page.NextCursor = keys.encodeCursor(&page.Items[len(page.Items)-1])
+	}
 
-	return restaurants, err
+	return page, nil
 }
 
-func (repo *RestaurantRepo) Create(object *models.Restaurant) error {
-	return repo.storage.Insert(object)
+// listNear orders restaurants by distance, so it's listed page by page only
+func (repo *RestaurantRepo) listNear(ctx context.Context, filter *models.RestaurantFilter, pagination *models.Pagination, pageNumber int, total int) (*models.RestaurantPage, error) {
+	if pagination.Cursor != "" {
+		return nil, ErrInvalidCursor
+	}
+
+	restaurants := []models.Restaurant{}
+	err := repo.storage.Find(ctx, nearQuery(filter)).
+		Select(bson.M{"menu": 0}).
+		Skip(pagination.PageSize * (pageNumber - 1)).
+		Limit(pagination.PageSize + 1).
+		All(&restaurants)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.RestaurantPage{Items: restaurants, Total: total, PageSize: pagination.PageSize}
+	if len(restaurants) > pagination.PageSize {
+		page.Items = restaurants[:pagination.PageSize]
+		page.NextPage = pageNumber + 1
+	}
+
+	for i := range page.Items {
+		if location := page.Items[i].Location; location != nil && len(location.Coordinates) == 2 {
+			distance := mongo.SphereDistance(filter.Near, location.Coordinates)
+			page.Items[i].Distance = &distance
+		}
+	}
+
+	return page, nil
 }
 
-func (repo *RestaurantRepo) Update(query *models.Restaurant, object *models.Restaurant) error {
-	return repo.storage.Update(query, bson.M{"$set": object})
+func (repo *RestaurantRepo) Get(ctx context.Context, query *models.Restaurant, withMenu bool) (*models.Restaurant, error) {
+	restaurant := &models.Restaurant{}
+
+	find := repo.storage.Find(ctx, restaurantQuery(query, false))
+	if !withMenu {
+		find = find.Select(bson.M{"menu": 0})
+	}
+
+	if err := find.One(restaurant); err != nil {
+		return nil, repo.conflict(ctx, query, false, err)
+	}
+
+	return restaurant, nil
 }
 
-func (repo *RestaurantRepo) Remove(query *models.Restaurant) error {
-	return repo.storage.Remove(query)
+// the id is generated here, so the caller knows what was stored,
+// a new restaurant has no reviews, so it has no rating, and it's not in the trash
+func (repo *RestaurantRepo) Create(ctx context.Context, object *models.Restaurant) error {
+	object.ID = bson.NewObjectId()
+	object.Version = 1
+	object.Rating = nil
+	object.DeletedAt = nil
+	return repo.storage.Insert(ctx, object)
 }
 
-func (repo *RestaurantRepo) AddDish(query *models.Restaurant, object *models.Dish) error {
+// Update replaces writable fields of the restaurant with ones of the object,
+// fields the object doesn't have are removed, the updated restaurant is returned
+func (repo *RestaurantRepo) Update(ctx context.Context, query *models.Restaurant, object *models.Restaurant) (*models.Restaurant, error) {
+	if err := repo.storage.Update(ctx, restaurantQuery(query, false), nextVersion(replaceFields(object))); err != nil {
+		return nil, repo.conflict(ctx, query, false, err)
+	}
+
+	return repo.Get(ctx, &models.Restaurant{ID: query.ID}, false)
+}
+
+// AddOwner lets the principal with the id change the restaurant, the changed restaurant is returned
+func (repo *RestaurantRepo) AddOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	return repo.updateOwners(ctx, query, bson.M{"$addToSet": bson.M{"owner_ids": ownerID}})
+}
+
+// RemoveOwner takes changing the restaurant away from the principal with the id
+func (repo *RestaurantRepo) RemoveOwner(ctx context.Context, query *models.Restaurant, ownerID string) (*models.Restaurant, error) {
+	return repo.updateOwners(ctx, query, bson.M{"$pull": bson.M{"owner_ids": ownerID}})
+}
+
+func (repo *RestaurantRepo) updateOwners(ctx context.Context, query *models.Restaurant, update bson.M) (*models.Restaurant, error) {
+	if err := repo.storage.Update(ctx, restaurantQuery(query, false), nextVersion(update)); err != nil {
+		return nil, repo.conflict(ctx, query, false, err)
+	}
+
+	return repo.Get(ctx, &models.Restaurant{ID: query.ID}, false)
+}
+
+// replaceFields sets writable fields stored for the object and unsets the rest of them,
+// empty fields are not stored, so they are unset as well
+func replaceFields(object *models.Restaurant) bson.M {
+	document := bson.M{}
+	data, _ := bson.Marshal(object)
+	bson.Unmarshal(data, document)
+
+	set, unset := bson.M{}, bson.M{}
+	for _, field := range writableFields {
+		if value, ok := document[field]; ok {
+			set[field] = value
+		} else {
+			unset[field] = ""
+		}
+	}
+
+	// mongo rejects empty operators
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	return update
+}
+
+// nextVersion adds the version increment to the update
+func nextVersion(update bson.M) bson.M {
+	update["$inc"] = bson.M{"version": 1}
+	return update
+}
+
+// restaurantQuery matches the query among restaurants in the trash or out of it
+func restaurantQuery(query *models.Restaurant, deleted bool) bson.M {
+	result := bson.M{}
+	data, _ := bson.Marshal(query)
+	bson.Unmarshal(data, result)
+	result["deleted_at"] = bson.M{"$exists": deleted}
+
+	return result
+}
+
+// conflict tells a stale version of the query apart from a missing restaurant,
+// conditional queries find neither of them
+func (repo *RestaurantRepo) conflict(ctx context.Context, query *models.Restaurant, deleted bool, err error) error {
+	if err != mgo.ErrNotFound || query.Version == 0 {
+		return err
+	}
+
+	stored := &models.Restaurant{}
+	find := repo.storage.Find(ctx, restaurantQuery(&models.Restaurant{ID: query.ID}, deleted))
+	if find.Select(bson.M{"version": 1}).One(stored) != nil || stored.Version == query.Version {
+		return err
+	}
+
+	return ErrVersionConflict
+}
+
+// Remove moves the restaurant to the trash, its menu and reviews are kept until it's purged
+func (repo *RestaurantRepo) Remove(ctx context.Context, query *models.Restaurant) error {
+	update := bson.M{"$set": bson.M{"deleted_at": time.Now().Truncate(time.Millisecond)}}
+	return repo.conflict(ctx, query, false, repo.storage.Update(ctx, restaurantQuery(query, false), nextVersion(update)))
+}
+
+// ListTrash lists removed restaurants page by page, the last removed go first
+func (repo *RestaurantRepo) ListTrash(ctx context.Context, pagination *models.Pagination) (*models.RestaurantPage, error) {
+	query := bson.M{"deleted_at": bson.M{"$exists": true}}
+	total, err := repo.storage.Find(ctx, query).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber := pagination.Page
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	restaurants := []models.Restaurant{}
+	err = repo.storage.Find(ctx, query).
+		Sort("-deleted_at", "-_id").
+		Select(bson.M{"menu": 0}).
+		Skip(pagination.PageSize * (pageNumber - 1)).
+		Limit(pagination.PageSize + 1).
+		All(&restaurants)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.RestaurantPage{Items: restaurants, Total: total, PageSize: pagination.PageSize}
+	if len(restaurants) > pagination.PageSize {
+		page.Items = restaurants[:pagination.PageSize]
+		page.NextPage = pageNumber + 1
+	}
+
+	return page, nil
+}
+
+// Restore takes the restaurant out of the trash, the restored restaurant is returned
+func (repo *RestaurantRepo) Restore(ctx context.Context, query *models.Restaurant) (*models.Restaurant, error) {
+	update := bson.M{"$unset": bson.M{"deleted_at": ""}}
+	if err := repo.storage.Update(ctx, restaurantQuery(query, true), nextVersion(update)); err != nil {
+		return nil, repo.conflict(ctx, query, true, err)
+	}
+
+	return repo.Get(ctx, &models.Restaurant{ID: query.ID}, false)
+}
+
+// Purge permanently removes restaurants that were moved to the trash before the time,
+// ids of the purged restaurants are returned to purge their reviews with ReviewRepo.Purge,
+// the ones purged before an error as well
+func (repo *RestaurantRepo) Purge(ctx context.Context, before time.Time) ([]bson.ObjectId, error) {
+	query := bson.M{"deleted_at": bson.M{"$lt": before}}
+
+	restaurants := []models.Restaurant{}
+	if err := repo.storage.Find(ctx, query).Select(bson.M{"_id": 1}).All(&restaurants); err != nil {
+		return nil, err
+	}
+
+	ids := []bson.ObjectId{}
+	for _, restaurant := range restaurants {
+		// a restaurant restored in the meantime doesn't match the query anymore
+		err := repo.storage.Remove(ctx, bson.M{"_id": restaurant.ID, "deleted_at": bson.M{"$lt": before}})
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, restaurant.ID)
+	}
+
+	return ids, nil
+}
+
+// SetRating sets the rating of the restaurant of query.Version, it's recomputed by ReviewRepo,
+// mgo.ErrNotFound means the restaurant was changed since the rating was computed
+func (repo *RestaurantRepo) SetRating(ctx context.Context, query *models.Restaurant, rating *models.Rating) error {
+	find := bson.M{"_id": query.ID, "version": query.Version}
+	if query.Version == 0 {
+		find["version"] = bson.M{"$exists": false}
+	}
+
+	return repo.storage.Update(ctx, find, nextVersion(bson.M{"$set": bson.M{"rating": rating}}))
+}
+
+// dish ids are generated here, so every dish of the menu is addressable
+func (repo *RestaurantRepo) AddDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	object.ID = bson.NewObjectId()
 	update := bson.M{"$push": bson.M{"menu": object}}
-	return repo.storage.Update(query, update)
+	return repo.conflict(ctx, query, false, repo.storage.Update(ctx, restaurantQuery(query, false), nextVersion(update)))
+}
+
+func (repo *RestaurantRepo) ListDish(ctx context.Context, query *models.Restaurant, objects *models.Menu) error {
+	return repo.storage.Find(ctx, restaurantQuery(query, false)).Select(bson.M{"menu": 1, "version": 1, "_id": 0}).One(objects)
+}
+
+// object.ID is used to find the dish, the rest of object is filled from the menu,
+// query.Version is set to the version of the restaurant the dish is read from
+func (repo *RestaurantRepo) GetDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	menu := &models.Menu{}
+	err := repo.storage.Find(ctx, dishQuery(query, object)).Select(bson.M{"menu": 1, "version": 1, "_id": 0}).One(menu)
+	if err != nil {
+		return repo.conflict(ctx, query, false, err)
+	}
+	query.Version = menu.Version
+
+	for _, dish := range menu.Menu {
+		if dish.ID == object.ID {
+			*object = dish
+			return nil
+		}
+	}
+
+	return mgo.ErrNotFound
+}
+
+// replaces the whole dish found by object.ID using the positional operator
+func (repo *RestaurantRepo) UpdateDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	update := bson.M{"$set": bson.M{"menu.$": object}}
+	return repo.conflict(ctx, query, false, repo.storage.Update(ctx, dishQuery(query, object), nextVersion(update)))
 }
 
-func (repo *RestaurantRepo) ListDish(query *models.Restaurant, objects *models.Menu) error {
-	return repo.storage.Find(query).Select(bson.M{"menu": 1, "_id": 0}).One(objects)
+func (repo *RestaurantRepo) RemoveDish(ctx context.Context, query *models.Restaurant, object *models.Dish) error {
+	update := bson.M{"$pull": bson.M{"menu": bson.M{"_id": object.ID}}}
+	return repo.conflict(ctx, query, false, repo.storage.Update(ctx, dishQuery(query, object), nextVersion(update)))
+}
+
+// dishQuery matches the restaurant only when it has the dish,
+// so a missing dish is reported as mgo.ErrNotFound
+func dishQuery(query *models.Restaurant, object *models.Dish) bson.M {
+	result := bson.M{"_id": query.ID, "menu._id": object.ID, "deleted_at": bson.M{"$exists": false}}
+	if query.Version != 0 {
+		result["version"] = query.Version
+	}
+
+	return result
+}
+
+// EnsureIndexes creates indexes queries of the repo rely on, it's safe to call it again
+func (repo *RestaurantRepo) EnsureIndexes() error {
+	for _, index := range []mgo.Index{textIndex, locationIndex} {
+		if err := repo.storage.EnsureIndex(index); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func NewRestaurantRepo() *RestaurantRepo {
-	collection := storages.GetStorage().C(models.RestaurantCollectionName)
-	dataAccess := &mongo.DataAccess{Collection: collection}
-	return &RestaurantRepo{storage: dataAccess}
+	dataAccess := storages.GetDataAccess(models.RestaurantCollectionName)
+	repo := &RestaurantRepo{storage: dataAccess}
+	if err := repo.EnsureIndexes(); err != nil {
+		logging.Default().WithError(err).Fatalf("Error creating indexes of %s", models.RestaurantCollectionName)
+	}
+
+	return repo
 }
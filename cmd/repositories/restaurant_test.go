@@ -1,23 +1,27 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"venues/cmd/fixtures"
 	"venues/cmd/models"
 	"venues/cmd/settings"
+	"venues/cmd/storages"
 
 	"venues/pkg/mongo"
 
-	"venues/cmd/storages"
-
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
+// ctx of operations in tests, none of them is cancelled
+var ctx = context.Background()
+
 type MockQuerier struct {
 	mock.Mock
 }
@@ -37,8 +41,13 @@ func (m *MockQuerier) One(model interface{}) error {
 	return args.Error(0)
 }
 
-func (m *MockQuerier) Sort(ordering string) mongo.Querier {
-	args := m.Called(ordering)
+func (m *MockQuerier) Count() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQuerier) Sort(fields ...string) mongo.Querier {
+	args := m.Called(fields)
 	return args.Get(0).(mongo.Querier)
 }
 
@@ -56,39 +65,79 @@ type MockDataAccess struct {
 	mock.Mock
 }
 
-func (m *MockDataAccess) Find(query interface{}) mongo.Querier {
+func (m *MockDataAccess) Find(ctx context.Context, query interface{}) mongo.Querier {
 	args := m.Called(query)
 	return args.Get(0).(mongo.Querier)
 }
 
-func (m *MockDataAccess) Insert(model interface{}) error {
+func (m *MockDataAccess) Insert(ctx context.Context, model interface{}) error {
 	args := m.Called(model)
 	return args.Error(0)
 }
 
-func (m *MockDataAccess) Update(query interface{}, model interface{}) error {
+func (m *MockDataAccess) Update(ctx context.Context, query interface{}, model interface{}) error {
 	args := m.Called(query, model)
 	return args.Error(0)
 }
 
-func (m *MockDataAccess) Remove(query interface{}) error {
+func (m *MockDataAccess) EnsureIndex(index mgo.Index) error {
+	args := m.Called(index)
+	return args.Error(0)
+}
+
+func (m *MockDataAccess) Remove(ctx context.Context, query interface{}) error {
 	args := m.Called(query)
 	return args.Error(0)
 }
 
+func (m *MockDataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	args := m.Called(query)
+	return args.Int(0), args.Error(1)
+}
+
+func firstPage() *models.Pagination {
+	return &models.Pagination{Page: 1, PageSize: 20}
+}
+
+// testStorage is emptied after every test of the suite
+type testStorage interface {
+	mongo.DataAccessor
+	DropCollection() error
+}
+
+// mongoTestStorage is the restaurants collection of the test database of mongod
+type mongoTestStorage struct {
+	*mongo.DataAccess
+}
+
+func (storage mongoTestStorage) DropCollection() error {
+	return storage.Collection.DropCollection()
+}
+
+func newMemoryTestStorage() testStorage {
+	return mongo.NewMemoryDataAccess()
+}
+
+func newMongoTestStorage() testStorage {
+	collection := storages.GetTestStorage().C(models.RestaurantCollectionName)
+	return mongoTestStorage{&mongo.DataAccess{Collection: collection}}
+}
+
 type RestaurantRepoTestSuite struct {
 	suite.Suite
 
-	storage *mgo.Collection
-	repo    *RestaurantRepo
+	newStorage func() testStorage
+	storage    testStorage
+	repo       *RestaurantRepo
 }
 
 func (suite *RestaurantRepoTestSuite) SetupTest() {
-	settings.Load()
-
-	suite.storage = storages.GetTestStorage().C(models.RestaurantCollectionName)
-	suite.repo = &RestaurantRepo{
-		storage: &mongo.DataAccess{Collection: suite.storage},
+	suite.storage = suite.newStorage()
+	// a collection left by a failed run of mongod suite would fail the test
+	suite.storage.DropCollection()
+	suite.repo = &RestaurantRepo{storage: suite.storage}
+	if err := suite.repo.EnsureIndexes(); err != nil {
+		suite.T().Fatal(err.Error())
 	}
 }
 
@@ -99,70 +148,257 @@ func (suite *RestaurantRepoTestSuite) TearDownTest() {
 func (suite *RestaurantRepoTestSuite) TestSuccessList() {
 	expected := fixtures.SimpleRestaurantSet()
 	for _, i := range expected {
-		if err := suite.storage.Insert(i); err != nil {
+		if err := suite.storage.Insert(ctx, i); err != nil {
 			suite.T().Fatal(err.Error())
 		}
 	}
 
-	result, err := suite.repo.List(&models.Restaurant{}, "", 0)
+	result, err := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
 
 	suite.Assertions.Nil(err)
-	suite.Assertions.Equal(result, expected)
+	suite.Assertions.Equal(result.Items, expected)
+	suite.Assertions.Equal(result.Total, len(expected))
+	suite.Assertions.Zero(result.NextPage)
+	suite.Assertions.Zero(result.NextCursor)
 }
 
 func (suite *RestaurantRepoTestSuite) TestFilterList() {
 	expected := fixtures.SimpleRestaurantSet()
 	for _, i := range expected {
-		if err := suite.storage.Insert(i); err != nil {
+		if err := suite.storage.Insert(ctx, i); err != nil {
 			suite.T().Fatal(err.Error())
 		}
 	}
 
-	filter := &models.Restaurant{City: "City1"}
-	result, err := suite.repo.List(filter, "", 0)
+	filter := &models.RestaurantFilter{City: "City1"}
+	result, err := suite.repo.List(ctx, filter, nil, firstPage())
 
 	suite.Assertions.Nil(err)
-	for _, i := range result {
+	suite.Assertions.Equal(result.Total, 1)
+	for _, i := range result.Items {
 		suite.Assertions.Equal(i.City, "City1")
 	}
 }
 
+func (suite *RestaurantRepoTestSuite) TestFilterOperatorsList() {
+	for _, i := range []models.Restaurant{
+		{ID: bson.NewObjectId(), Name: "Top Cafe", City: "Moscow", Rating: &models.Rating{Score: 8}, Menu: []models.Dish{{Name: "Soup", Price: 500}}},
+		{ID: bson.NewObjectId(), Name: "Top.Bar", City: "Paris", Rating: &models.Rating{Score: 4}, Menu: []models.Dish{{Name: "Wine", Price: 1500}}},
+		{ID: bson.NewObjectId(), Name: "Bottom", City: "Berlin"},
+	} {
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+
+	zero := float32(0)
+	five := float32(5)
+	withMenu := true
+	withoutMenu := false
+	price := 1000
+
+	for filter, expected := range map[*models.RestaurantFilter][]string{
+		{}:                                     {"Top Cafe", "Top.Bar", "Bottom"},
+		{CityIn: []string{"Moscow", "Berlin"}}: {"Top Cafe", "Bottom"},
+		{NamePrefix: "Top."}:                   {"Top.Bar"},
+		{NamePrefix: "top"}:                    {},
+		{RatingGte: &five}:                     {"Top Cafe"},
+		{RatingGte: &zero}:                     {"Top Cafe", "Top.Bar", "Bottom"},
+		{RatingLte: &five}:                     {"Top.Bar", "Bottom"},
+		{HasMenu: &withMenu}:                   {"Top Cafe", "Top.Bar"},
+		{HasMenu: &withoutMenu}:                {"Bottom"},
+		{DishPriceLte: &price}:                 {"Top Cafe"},
+		{NamePrefix: "Top", RatingLte: &five}:  {"Top.Bar"},
+	} {
+		result, err := suite.repo.List(ctx, filter, nil, firstPage())
+		suite.Assertions.Nil(err)
+
+		names := []string{}
+		for _, i := range result.Items {
+			names = append(names, i.Name)
+		}
+		suite.Assertions.ElementsMatch(names, expected, "%+v", filter)
+		suite.Assertions.Equal(result.Total, len(expected))
+	}
+}
+
 func (suite *RestaurantRepoTestSuite) TestOrderingList() {
 	expected := fixtures.SimpleRestaurantSet()
 	for _, i := range expected {
-		if err := suite.storage.Insert(i); err != nil {
+		if err := suite.storage.Insert(ctx, i); err != nil {
 			suite.T().Fatal(err.Error())
 		}
 	}
 
-	ordering := "-rating"
-	result, err := suite.repo.List(&models.Restaurant{}, ordering, 0)
+	ordering := models.Ordering{{Field: "rating.score", Descending: true}}
+	result, err := suite.repo.List(ctx, &models.RestaurantFilter{}, ordering, firstPage())
 
 	suite.Assertions.Nil(err)
-	suite.Assertions.True(result[0].Rating > result[1].Rating)
+	suite.Assertions.True(result.Items[0].Rating.Score > result.Items[1].Rating.Score)
 }
 
 func (suite *RestaurantRepoTestSuite) TestPaginateList() {
 	expected := fixtures.SimpleRestaurantSet()
 	for _, i := range expected {
-		if err := suite.storage.Insert(i); err != nil {
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+
+	result, err := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, &models.Pagination{Page: 2, PageSize: 20})
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(len(result.Items) == 0)
+	suite.Assertions.Equal(result.Total, len(expected))
+
+	result, err = suite.repo.List(ctx, &models.RestaurantFilter{}, nil, &models.Pagination{Page: 1, PageSize: 1})
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Items, expected[:1])
+	suite.Assertions.Equal(result.NextPage, 2)
+	suite.Assertions.NotZero(result.NextCursor)
+}
+
+func (suite *RestaurantRepoTestSuite) TestCursorList() {
+	restaurants := []models.Restaurant{
+		{Name: "B", City: "Moscow", Rating: &models.Rating{Score: 5}},
+		{Name: "A", City: "Paris"},
+		{Name: "C", Rating: &models.Rating{Score: 7.5}},
+		{Name: "A", City: "Moscow", Rating: &models.Rating{Score: 5}},
+		{Name: "B"},
+		{Name: "C", City: "Paris", Rating: &models.Rating{Score: 3}},
+	}
+	for _, i := range restaurants {
+		i.ID = bson.NewObjectId()
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+
+	for _, ordering := range []models.Ordering{
+		nil,
+		{{Field: "rating.score"}},
+		{{Field: "rating.score", Descending: true}},
+		{{Field: "rating.score", Descending: true}, {Field: "name"}},
+		{{Field: "city"}, {Field: "name", Descending: true}},
+		{{Field: "_id", Descending: true}},
+	} {
+		keys := newKeyset(ordering)
+		var expected []models.Restaurant
+		suite.storage.Find(ctx, nil).Sort(keys.sort()...).All(&expected)
+
+		var result []models.Restaurant
+		pagination := &models.Pagination{PageSize: 2}
+		for {
+			page, err := suite.repo.List(ctx, &models.RestaurantFilter{}, ordering, pagination)
+			suite.Assertions.Nil(err)
+			suite.Assertions.Equal(page.Total, len(restaurants))
+
+			result = append(result, page.Items...)
+			if page.NextCursor == "" {
+				break
+			}
+			pagination = &models.Pagination{PageSize: 2, Cursor: page.NextCursor}
+		}
+
+		suite.Assertions.Equal(result, expected, ordering.String())
+	}
+}
+
+func (suite *RestaurantRepoTestSuite) TestCursorListInvalid() {
+	_, err := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, &models.Pagination{PageSize: 2, Cursor: "bad-cursor"})
+	suite.Assertions.Equal(err, ErrInvalidCursor)
+
+	cursor := newKeyset(models.Ordering{{Field: "rating.score"}}).encodeCursor(&models.Restaurant{ID: bson.NewObjectId()})
+	_, err = suite.repo.List(ctx, &models.RestaurantFilter{}, models.Ordering{{Field: "rating.score", Descending: true}}, &models.Pagination{PageSize: 2, Cursor: cursor})
+	suite.Assertions.Equal(err, ErrInvalidCursor)
+}
+
+func (suite *RestaurantRepoTestSuite) TestNearList() {
+	for _, i := range []models.Restaurant{
+		{ID: bson.NewObjectId(), Name: "Arbat", City: "Moscow", Location: models.NewGeoPoint(37.5920, 55.7500)},
+		{ID: bson.NewObjectId(), Name: "Kremlin", City: "Moscow", Location: models.NewGeoPoint(37.6175, 55.7520)},
+		{ID: bson.NewObjectId(), Name: "Airport", City: "Moscow", Location: models.NewGeoPoint(37.4146, 55.9726)},
+		{ID: bson.NewObjectId(), Name: "Nowhere", City: "Moscow"},
+		{ID: bson.NewObjectId(), Name: "Square", City: "Other", Location: models.NewGeoPoint(37.6180, 55.7530)},
+	} {
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+
+	radius := 5000.0
+	filter := &models.RestaurantFilter{City: "Moscow", Near: []float64{37.6180, 55.7525}, RadiusM: &radius}
+	result, err := suite.repo.List(ctx, filter, nil, firstPage())
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Total, 2)
+	suite.Assertions.Len(result.Items, 2)
+	suite.Assertions.Equal(result.Items[0].Name, "Kremlin")
+	suite.Assertions.Equal(result.Items[1].Name, "Arbat")
+	suite.Assertions.True(*result.Items[0].Distance < *result.Items[1].Distance)
+	suite.Assertions.InDelta(*result.Items[1].Distance, 1650, 20)
+
+	result, err = suite.repo.List(ctx, filter, nil, &models.Pagination{Page: 2, PageSize: 1})
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Items[0].Name, "Arbat")
+	suite.Assertions.Zero(result.NextPage)
+	suite.Assertions.Zero(result.NextCursor)
+
+	_, err = suite.repo.List(ctx, filter, nil, &models.Pagination{PageSize: 1, Cursor: "abc"})
+	suite.Assertions.Equal(err, ErrInvalidCursor)
+}
+
+func (suite *RestaurantRepoTestSuite) TestSearch() {
+	for _, i := range []models.Restaurant{
+		{ID: bson.NewObjectId(), Name: "Pizza Place", City: "Moscow"},
+		{ID: bson.NewObjectId(), Name: "Bistro", City: "Paris", Menu: []models.Dish{
+			{ID: bson.NewObjectId(), Name: "Pizza <Margherita>", Price: 900},
+			{ID: bson.NewObjectId(), Name: "Soup", Price: 500},
+		}},
+		{ID: bson.NewObjectId(), Name: "Sushi Bar", City: "Berlin"},
+	} {
+		if err := suite.storage.Insert(ctx, i); err != nil {
 			suite.T().Fatal(err.Error())
 		}
 	}
 
-	result, err := suite.repo.List(&models.Restaurant{}, "", 2)
+	result, err := suite.repo.Search(ctx, "PIZZA", firstPage())
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Total, 2)
+	suite.Assertions.Len(result.Items, 2)
+	suite.Assertions.Equal(result.Items[0].Name, "Pizza Place")
+	suite.Assertions.Empty(result.Items[0].Dishes)
+	suite.Assertions.Equal(result.Items[1].Name, "Bistro")
+	suite.Assertions.True(result.Items[0].Score > result.Items[1].Score)
+	suite.Assertions.Nil(result.Items[1].Menu)
+	suite.Assertions.Len(result.Items[1].Dishes, 1)
+	suite.Assertions.Equal(result.Items[1].Dishes[0].Name, "Pizza <Margherita>")
+	suite.Assertions.Equal(result.Items[1].Dishes[0].Highlight, "<em>Pizza</em> &lt;Margherita&gt;")
+
+	result, err = suite.repo.Search(ctx, "pizza", &models.Pagination{Page: 1, PageSize: 1})
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result.Items, 1)
+	suite.Assertions.Equal(result.NextPage, 2)
+
+	result, err = suite.repo.Search(ctx, "noodles", firstPage())
 
 	suite.Assertions.Nil(err)
-	suite.Assertions.True(len(result) == 0)
+	suite.Assertions.Zero(result.Total)
+	suite.Assertions.Equal(result.Items, []models.SearchResult{})
 }
 
 func (suite *RestaurantRepoTestSuite) TestEmptyList() {
-	var expected []models.Restaurant
+	expected := []models.Restaurant{}
 
-	result, err := suite.repo.List(&models.Restaurant{}, "", 0)
+	result, err := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
 
 	suite.Assertions.Nil(err)
-	suite.Assertions.Equal(result, expected)
+	suite.Assertions.Equal(result.Items, expected)
+	suite.Assertions.Zero(result.Total)
 }
 
 func (suite *RestaurantRepoTestSuite) TestErrorList() {
@@ -174,36 +410,80 @@ func (suite *RestaurantRepoTestSuite) TestErrorList() {
 		"Find",
 		mock.MatchedBy(func(i interface{}) bool { return true }),
 	).Return(mockedQuerier)
+	mockedQuerier.On("Count").Return(0, nil)
+	mockedQuerier.On("Skip", 0).Return(mockedQuerier)
+	mockedQuerier.On("Sort", []string{"_id"}).Return(mockedQuerier)
 	mockedQuerier.On(
 		"Select",
 		bson.M{"menu": 0},
 	).Return(mockedQuerier)
+	mockedQuerier.On("Limit", 21).Return(mockedQuerier)
 	mockedQuerier.On(
 		"All",
 		mock.MatchedBy(func(i interface{}) bool { return true }),
 	).Return(errors.New("mocked error"))
 
-	_, err := suite.repo.List(&models.Restaurant{}, "", 0)
+	_, err := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
 
 	mockedStorage.AssertExpectations(suite.T())
 	mockedQuerier.AssertExpectations(suite.T())
 	suite.Assertions.Error(err)
 }
 
+func (suite *RestaurantRepoTestSuite) TestGetSuccess() {
+	expected := fixtures.SimpleRestaurantSet()
+	for _, i := range expected {
+		i.Menu = []models.Dish{{ID: bson.NewObjectId(), Name: "Name", Price: 100}}
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+
+	result, err := suite.repo.Get(ctx, &models.Restaurant{ID: expected[1].ID}, false)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result, &expected[1])
+
+	result, err = suite.repo.Get(ctx, &models.Restaurant{ID: expected[1].ID}, true)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result.Menu, 1)
+}
+
+func (suite *RestaurantRepoTestSuite) TestGetNotFound() {
+	_, err := suite.repo.Get(ctx, &models.Restaurant{ID: bson.NewObjectId()}, false)
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
 func (suite *RestaurantRepoTestSuite) TestCreateSuccess() {
-	data, _ := suite.repo.List(&models.Restaurant{}, "", 0)
-	suite.Assertions.Zero(data)
+	data, _ := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
+	suite.Assertions.Empty(data.Items)
 
-	expected := &models.Restaurant{Name: "Name"}
-	err := suite.repo.Create(expected)
+	expected := &models.Restaurant{Name: "Name", Rating: &models.Rating{Count: 1, Average: 10, Score: 10}}
+	err := suite.repo.Create(ctx, expected)
 	suite.Assertions.Nil(err)
+	suite.Assertions.True(expected.ID.Valid())
+	suite.Assertions.Nil(expected.Rating)
 
 	result := &models.Restaurant{}
-	suite.repo.storage.Find(expected).One(result)
+	suite.repo.storage.Find(ctx, expected).One(result)
 	expected.ID = result.ID
 	suite.Assertions.Equal(result, expected)
 }
 
+func (suite *RestaurantRepoTestSuite) TestCreateNotInTrash() {
+	deletedAt := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	object := &models.Restaurant{Name: "Name", DeletedAt: &deletedAt}
+
+	suite.Assertions.Nil(suite.repo.Create(ctx, object))
+	suite.Assertions.Nil(object.DeletedAt)
+
+	result, err := suite.repo.Get(ctx, &models.Restaurant{ID: object.ID}, false)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.ID, object.ID)
+	data, _ := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
+	suite.Assertions.Len(data.Items, 1)
+}
+
 func (suite *RestaurantRepoTestSuite) TestCreateError() {
 	mockAccess := &MockDataAccess{}
 	suite.repo = &RestaurantRepo{storage: mockAccess}
@@ -211,23 +491,46 @@ func (suite *RestaurantRepoTestSuite) TestCreateError() {
 	object := &models.Restaurant{Name: "Name"}
 	mockAccess.On("Insert", object).Return(errors.New("mocked error"))
 
-	err := suite.repo.Create(object)
+	err := suite.repo.Create(ctx, object)
 
 	mockAccess.AssertExpectations(suite.T())
 	suite.Assertions.Error(err)
 }
 
 func (suite *RestaurantRepoTestSuite) TestUpdateSuccess() {
-	object := &models.Restaurant{Name: "Name"}
-	suite.repo.Create(object)
+	object := &models.Restaurant{Name: "Name", City: "City", Address: &models.Address{Street: "Street"}}
+	suite.repo.Create(ctx, object)
 
-	update := &models.Restaurant{City: "City33"}
-	err := suite.repo.Update(object, update)
+	update := &models.Restaurant{Name: "Updated", City: "City33"}
+	result, err := suite.repo.Update(ctx, &models.Restaurant{ID: object.ID}, update)
 	suite.Assertions.Nil(err)
 
-	result := &models.Restaurant{}
-	suite.repo.storage.Find(update).One(result)
-	suite.Assertions.Equal(result, &models.Restaurant{ID: result.ID, Name: object.Name, City: update.City})
+	expected := &models.Restaurant{ID: object.ID, Name: update.Name, City: update.City, Version: 2}
+	suite.Assertions.Equal(result, expected)
+
+	stored := &models.Restaurant{}
+	suite.repo.storage.Find(ctx, bson.M{"_id": object.ID}).One(stored)
+	suite.Assertions.Equal(stored, expected)
+}
+
+func (suite *RestaurantRepoTestSuite) TestUpdateKeepsMenuAndRating() {
+	rating := &models.Rating{Count: 1, Average: 8, Score: 5.5}
+	menu := []models.Dish{{ID: bson.NewObjectId(), Name: "Soup", Price: 500}}
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name", Rating: rating, Menu: menu}
+	suite.storage.Insert(ctx, object)
+
+	result, err := suite.repo.Update(ctx, &models.Restaurant{ID: object.ID}, &models.Restaurant{Name: "Updated", Rating: &models.Rating{Score: 10}})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result, &models.Restaurant{ID: object.ID, Name: "Updated", Rating: rating, Version: 1})
+
+	result, _ = suite.repo.Get(ctx, &models.Restaurant{ID: object.ID}, true)
+	suite.Assertions.Equal(result.Menu, menu)
+}
+
+func (suite *RestaurantRepoTestSuite) TestUpdateNotFound() {
+	_, err := suite.repo.Update(ctx, &models.Restaurant{ID: bson.NewObjectId()}, &models.Restaurant{Name: "Name"})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
 }
 
 func (suite *RestaurantRepoTestSuite) TestUpdateError() {
@@ -236,27 +539,38 @@ func (suite *RestaurantRepoTestSuite) TestUpdateError() {
 
 	object := &models.Restaurant{Name: "Name"}
 	update := &models.Restaurant{Name: "Name333"}
-	mockAccess.On("Update", object, bson.M{"$set": update}).Return(errors.New("mocked error"))
+	mockAccess.On("Update", bson.M{"name": "Name", "deleted_at": bson.M{"$exists": false}}, bson.M{
+		"$set":   bson.M{"name": "Name333"},
+		"$unset": bson.M{"city": "", "location": "", "address": ""},
+		"$inc":   bson.M{"version": 1},
+	}).Return(errors.New("mocked error"))
 
-	err := suite.repo.Update(object, update)
+	_, err := suite.repo.Update(ctx, object, update)
 
 	mockAccess.AssertExpectations(suite.T())
 	suite.Assertions.Error(err)
 }
 
 func (suite *RestaurantRepoTestSuite) TestRemoveSuccess() {
-	data, _ := suite.repo.List(&models.Restaurant{}, "", 0)
-	suite.Assertions.Zero(data)
+	data, _ := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
+	suite.Assertions.Empty(data.Items)
 
 	object := &models.Restaurant{Name: "Name"}
-	err := suite.repo.Create(object)
+	err := suite.repo.Create(ctx, object)
 	suite.Assertions.Nil(err)
 
-	err = suite.repo.Remove(object)
+	err = suite.repo.Remove(ctx, object)
 	suite.Assertions.Nil(err)
 
-	data, _ = suite.repo.List(&models.Restaurant{}, "", 0)
-	suite.Assertions.Zero(data)
+	data, _ = suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
+	suite.Assertions.Empty(data.Items)
+
+	_, err = suite.repo.Get(ctx, &models.Restaurant{ID: object.ID}, false)
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+	err = suite.repo.ListDish(ctx, &models.Restaurant{ID: object.ID}, &models.Menu{})
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+	err = suite.repo.Remove(ctx, &models.Restaurant{ID: object.ID})
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
 }
 
 func (suite *RestaurantRepoTestSuite) TestRemoveError() {
@@ -264,9 +578,9 @@ func (suite *RestaurantRepoTestSuite) TestRemoveError() {
 	suite.repo = &RestaurantRepo{storage: mockAccess}
 
 	object := &models.Restaurant{Name: "Name"}
-	mockAccess.On("Remove", object).Return(errors.New("mocked error"))
+	mockAccess.On("Update", bson.M{"name": "Name", "deleted_at": bson.M{"$exists": false}}, mock.Anything).Return(errors.New("mocked error"))
 
-	err := suite.repo.Remove(object)
+	err := suite.repo.Remove(ctx, object)
 
 	mockAccess.AssertExpectations(suite.T())
 	suite.Assertions.Error(err)
@@ -274,18 +588,222 @@ func (suite *RestaurantRepoTestSuite) TestRemoveError() {
 
 func (suite *RestaurantRepoTestSuite) TestPushDishSuccess() {
 	object := &models.Restaurant{Name: "Name"}
-	suite.repo.Create(object)
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
 
 	dish := &models.Dish{Name: "Name"}
-	err := suite.repo.AddDish(object, dish)
+	err := suite.repo.AddDish(ctx, query, dish)
 	suite.Assertions.Nil(err)
 
 	result := &models.Restaurant{}
-	suite.repo.storage.Find(object).One(result)
+	suite.repo.storage.Find(ctx, query).One(result)
 
 	suite.Assertions.Equal(&result.Menu[0], dish)
 }
 
+func (suite *RestaurantRepoTestSuite) TestGetDishSuccess() {
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	dish := &models.Dish{Name: "Name", Price: 100}
+	suite.repo.AddDish(ctx, query, dish)
+	suite.Assertions.True(dish.ID.Valid())
+
+	result := &models.Dish{ID: dish.ID}
+	err := suite.repo.GetDish(ctx, query, result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result, dish)
+}
+
+func (suite *RestaurantRepoTestSuite) TestGetDishNotFound() {
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+	suite.repo.AddDish(ctx, query, &models.Dish{Name: "Name", Price: 100})
+
+	err := suite.repo.GetDish(ctx, query, &models.Dish{ID: bson.NewObjectId()})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *RestaurantRepoTestSuite) TestUpdateDishSuccess() {
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	first, second := &models.Dish{Name: "First", Price: 100}, &models.Dish{Name: "Second", Price: 200}
+	suite.repo.AddDish(ctx, query, first)
+	suite.repo.AddDish(ctx, query, second)
+
+	update := &models.Dish{ID: second.ID, Name: "Updated", Price: 300}
+	err := suite.repo.UpdateDish(ctx, query, update)
+	suite.Assertions.Nil(err)
+
+	menu := &models.Menu{}
+	suite.repo.ListDish(ctx, query, menu)
+	suite.Assertions.Equal(menu.Menu, []models.Dish{*first, *update})
+}
+
+func (suite *RestaurantRepoTestSuite) TestUpdateDishNotFound() {
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	err := suite.repo.UpdateDish(ctx, query, &models.Dish{ID: bson.NewObjectId(), Name: "Name", Price: 100})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *RestaurantRepoTestSuite) TestRemoveDishSuccess() {
+	object := &models.Restaurant{ID: bson.NewObjectId(), Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	first, second := &models.Dish{Name: "First", Price: 100}, &models.Dish{Name: "Second", Price: 200}
+	suite.repo.AddDish(ctx, query, first)
+	suite.repo.AddDish(ctx, query, second)
+
+	err := suite.repo.RemoveDish(ctx, query, first)
+	suite.Assertions.Nil(err)
+
+	menu := &models.Menu{}
+	suite.repo.ListDish(ctx, query, menu)
+	suite.Assertions.Equal(menu.Menu, []models.Dish{*second})
+
+	err = suite.repo.RemoveDish(ctx, query, first)
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *RestaurantRepoTestSuite) TestVersionConflict() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(ctx, object)
+	suite.Assertions.Equal(object.Version, 1)
+
+	stale := &models.Restaurant{ID: object.ID, Version: 1}
+	result, err := suite.repo.Update(ctx, stale, &models.Restaurant{Name: "Updated"})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Version, 2)
+
+	_, err = suite.repo.Get(ctx, stale, false)
+	suite.Assertions.Equal(err, ErrVersionConflict)
+	_, err = suite.repo.Update(ctx, stale, &models.Restaurant{Name: "Lost"})
+	suite.Assertions.Equal(err, ErrVersionConflict)
+	suite.Assertions.Equal(suite.repo.AddDish(ctx, stale, &models.Dish{Name: "Soup", Price: 100}), ErrVersionConflict)
+	suite.Assertions.Equal(suite.repo.Remove(ctx, stale), ErrVersionConflict)
+
+	current := &models.Restaurant{ID: object.ID, Version: 2}
+	dish := &models.Dish{Name: "Soup", Price: 100}
+	suite.Assertions.Nil(suite.repo.AddDish(ctx, current, dish))
+
+	query := &models.Restaurant{ID: object.ID}
+	suite.Assertions.Nil(suite.repo.GetDish(ctx, query, &models.Dish{ID: dish.ID}))
+	suite.Assertions.Equal(query.Version, 3)
+
+	suite.Assertions.Equal(suite.repo.UpdateDish(ctx, current, dish), ErrVersionConflict)
+	suite.Assertions.Equal(suite.repo.RemoveDish(ctx, current, dish), ErrVersionConflict)
+	suite.Assertions.Equal(suite.repo.RemoveDish(ctx, query, &models.Dish{ID: bson.NewObjectId()}), mgo.ErrNotFound)
+	suite.Assertions.Equal(suite.repo.Remove(ctx, &models.Restaurant{ID: bson.NewObjectId(), Version: 1}), mgo.ErrNotFound)
+	suite.Assertions.Nil(suite.repo.Remove(ctx, query))
+}
+
+func (suite *RestaurantRepoTestSuite) TestOwners() {
+	object := &models.Restaurant{Name: "Name"}
+	suite.repo.Create(ctx, object)
+	query := &models.Restaurant{ID: object.ID}
+
+	result, err := suite.repo.AddOwner(ctx, query, "jwt:ann")
+	suite.Assertions.Nil(err)
+	result, err = suite.repo.AddOwner(ctx, query, "jwt:ann")
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.OwnerIDs, []string{"jwt:ann"})
+	suite.Assertions.Equal(result.Version, 3)
+
+	_, err = suite.repo.RemoveOwner(ctx, &models.Restaurant{ID: object.ID, Version: 1}, "jwt:ann")
+	suite.Assertions.Equal(err, ErrVersionConflict)
+
+	result, err = suite.repo.RemoveOwner(ctx, query, "jwt:ann")
+	suite.Assertions.Nil(err)
+	suite.Assertions.Empty(result.OwnerIDs)
+
+	_, err = suite.repo.AddOwner(ctx, &models.Restaurant{ID: bson.NewObjectId()}, "jwt:ann")
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *RestaurantRepoTestSuite) TestTrash() {
+	first, second := &models.Restaurant{Name: "First"}, &models.Restaurant{Name: "Second"}
+	suite.repo.Create(ctx, first)
+	suite.repo.Create(ctx, second)
+	suite.repo.Create(ctx, &models.Restaurant{Name: "Kept"})
+	suite.repo.AddDish(ctx, &models.Restaurant{ID: first.ID}, &models.Dish{Name: "Soup", Price: 100})
+
+	suite.Assertions.Nil(suite.repo.Remove(ctx, &models.Restaurant{ID: first.ID}))
+	time.Sleep(time.Millisecond)
+	suite.Assertions.Nil(suite.repo.Remove(ctx, &models.Restaurant{ID: second.ID}))
+
+	page, err := suite.repo.ListTrash(ctx, &models.Pagination{Page: 1, PageSize: 1})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(page.Total, 2)
+	suite.Assertions.Equal(page.NextPage, 2)
+	suite.Assertions.Equal(page.Items[0].Name, "Second")
+	suite.Assertions.NotNil(page.Items[0].DeletedAt)
+
+	data, _ := suite.repo.List(ctx, &models.RestaurantFilter{}, nil, firstPage())
+	suite.Assertions.Equal(data.Total, 1)
+	search, _ := suite.repo.Search(ctx, "First", firstPage())
+	suite.Assertions.Zero(search.Total)
+
+	_, err = suite.repo.Restore(ctx, &models.Restaurant{ID: first.ID, Version: 2})
+	suite.Assertions.Equal(err, ErrVersionConflict)
+
+	result, err := suite.repo.Restore(ctx, &models.Restaurant{ID: first.ID, Version: 3})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Version, 4)
+	suite.Assertions.Nil(result.DeletedAt)
+
+	_, err = suite.repo.Restore(ctx, &models.Restaurant{ID: first.ID})
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+
+	menu := &models.Menu{}
+	suite.Assertions.Nil(suite.repo.ListDish(ctx, &models.Restaurant{ID: first.ID}, menu))
+	suite.Assertions.Len(menu.Menu, 1)
+}
+
+func (suite *RestaurantRepoTestSuite) TestPurge() {
+	old, recent := &models.Restaurant{Name: "Old"}, &models.Restaurant{Name: "Recent"}
+	suite.repo.Create(ctx, old)
+	suite.repo.Create(ctx, recent)
+	suite.repo.Create(ctx, &models.Restaurant{Name: "Kept"})
+	suite.repo.Remove(ctx, &models.Restaurant{ID: old.ID})
+	time.Sleep(time.Millisecond)
+	before := time.Now()
+	time.Sleep(time.Millisecond)
+	suite.repo.Remove(ctx, &models.Restaurant{ID: recent.ID})
+
+	ids, err := suite.repo.Purge(ctx, before)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(ids, []bson.ObjectId{old.ID})
+
+	count, _ := suite.storage.Find(ctx, nil).Count()
+	suite.Assertions.Equal(count, 2)
+
+	ids, err = suite.repo.Purge(ctx, before)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Empty(ids)
+}
+
 func TestRestaurantRepoTestSuite(t *testing.T) {
-	suite.Run(t, new(RestaurantRepoTestSuite))
+	suite.Run(t, &RestaurantRepoTestSuite{newStorage: newMemoryTestStorage})
+}
+
+// TestRestaurantRepoMongoTestSuite checks the same suite against mongod, the memory storage only emulates it,
+// it's skipped unless MONGO_ADDRESS and MONGO_DB_NAME_TEST are set
+func TestRestaurantRepoMongoTestSuite(t *testing.T) {
+	settings.Load()
+	if settings.GetSetting("MONGO_ADDRESS", "") == "" || settings.GetSetting("MONGO_DB_NAME_TEST", "") == "" {
+		t.Skip("MONGO_ADDRESS and MONGO_DB_NAME_TEST are not set")
+	}
+
+	suite.Run(t, &RestaurantRepoTestSuite{newStorage: newMongoTestStorage})
 }
@@ -0,0 +1,183 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"venues/cmd/models"
+	"venues/cmd/storages"
+	"venues/pkg/logging"
+	"venues/pkg/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	_ ReviewAccessor = new(ReviewRepo)
+)
+
+// ratingAttempts is how many times the rating is recomputed while other changes of the restaurant race with it
+const ratingAttempts = 3
+
+// reviewIndex lists reviews of a restaurant from the newest one
+var reviewIndex = mgo.Index{
+	Key:  []string{"restaurant_id", "-created_at", "-_id"},
+	Name: "reviews_restaurant",
+}
+
+type ReviewAccessor interface {
+	Create(context.Context, *models.Restaurant, *models.Review) error
+	List(context.Context, *models.Restaurant, *models.Pagination) (*models.ReviewPage, error)
+}
+
+// ReviewRepo stores reviews in their own collection
+// and keeps the rating of restaurants up to date with them, the rating is set through ratings,
+// so it's changed the way other fields of restaurants are, like audited
+type ReviewRepo struct {
+	storage     mongo.DataAccessor
+	restaurants mongo.DataAccessor
+	ratings     RestaurantAccessor
+}
+
+// Create reports mgo.ErrNotFound for a missing restaurant,
+// the id, restaurant and timestamps of the review are set here.
+// The review is created even if the rating isn't updated, failing it would make the client store it again,
+// the rating is fixed by the next review then
+func (repo *ReviewRepo) Create(ctx context.Context, query *models.Restaurant, object *models.Review) error {
+	if err := repo.checkRestaurant(ctx, query); err != nil {
+		return err
+	}
+
+	// mongo keeps milliseconds, so the review is returned as it's stored
+	now := time.Now().Truncate(time.Millisecond)
+	object.ID = bson.NewObjectId()
+	object.RestaurantID = query.ID
+	object.CreatedAt = now
+	object.UpdatedAt = now
+	if err := repo.storage.Insert(ctx, object); err != nil {
+		return err
+	}
+
+	if err := repo.updateRating(ctx, query); err != nil {
+		logging.FromContext(ctx).WithError(err).With("restaurant_id", query.ID.Hex()).Error("Error updating the rating")
+	}
+
+	return nil
+}
+
+// updateRating recomputes the rating from every review of the restaurant instead of changing it by the new one,
+// the rating is saved only if the restaurant isn't changed since the reviews are read,
+// otherwise it's recomputed with reviews created meanwhile
+func (repo *ReviewRepo) updateRating(ctx context.Context, query *models.Restaurant) error {
+	for attempt := 1; ; attempt++ {
+		restaurant := &models.Restaurant{}
+		err := repo.restaurants.Find(ctx, bson.M{"_id": query.ID}).Select(bson.M{"version": 1}).One(restaurant)
+		if err != nil {
+			return err
+		}
+
+		reviews := []models.Review{}
+		err = repo.storage.Find(ctx, bson.M{"restaurant_id": query.ID}).Select(bson.M{"score": 1}).All(&reviews)
+		if err != nil {
+			return err
+		}
+
+		scores := make([]int, len(reviews))
+		for i, review := range reviews {
+			scores[i] = review.Score
+		}
+
+		err = repo.ratings.SetRating(ctx, &models.Restaurant{ID: query.ID, Version: restaurant.Version}, models.NewRating(scores))
+		if err != mgo.ErrNotFound || attempt == ratingAttempts {
+			return err
+		}
+	}
+}
+
+func (repo *ReviewRepo) List(ctx context.Context, query *models.Restaurant, pagination *models.Pagination) (*models.ReviewPage, error) {
+	if err := repo.checkRestaurant(ctx, query); err != nil {
+		return nil, err
+	}
+
+	find := bson.M{"restaurant_id": query.ID}
+	total, err := repo.storage.Find(ctx, find).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber := pagination.Page
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	reviews := []models.Review{}
+	err = repo.storage.Find(ctx, find).
+		Sort("-created_at", "-_id").
+		Skip(pagination.PageSize * (pageNumber - 1)).
+		Limit(pagination.PageSize + 1).
+		All(&reviews)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.ReviewPage{Items: reviews, Total: total, PageSize: pagination.PageSize}
+	if len(reviews) > pagination.PageSize {
+		page.Items = reviews[:pagination.PageSize]
+		page.NextPage = pageNumber + 1
+	}
+
+	return page, nil
+}
+
+// Purge removes reviews of the restaurants, unless a restaurant still exists,
+// restaurants restored while they were purged keep their reviews
+func (repo *ReviewRepo) Purge(ctx context.Context, ids []bson.ObjectId) error {
+	existing := []models.Restaurant{}
+	if err := repo.restaurants.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}).Select(bson.M{"_id": 1}).All(&existing); err != nil {
+		return err
+	}
+
+	kept := map[bson.ObjectId]bool{}
+	for _, restaurant := range existing {
+		kept[restaurant.ID] = true
+	}
+
+	purged := []bson.ObjectId{}
+	for _, id := range ids {
+		if !kept[id] {
+			purged = append(purged, id)
+		}
+	}
+	if len(purged) == 0 {
+		return nil
+	}
+
+	_, err := repo.storage.RemoveAll(ctx, bson.M{"restaurant_id": bson.M{"$in": purged}})
+	return err
+}
+
+// checkRestaurant reports mgo.ErrNotFound when there is no such restaurant or it's in the trash
+func (repo *ReviewRepo) checkRestaurant(ctx context.Context, query *models.Restaurant) error {
+	find := bson.M{"_id": query.ID, "deleted_at": bson.M{"$exists": false}}
+	return repo.restaurants.Find(ctx, find).Select(bson.M{"_id": 1}).One(&models.Restaurant{})
+}
+
+// EnsureIndexes creates indexes queries of the repo rely on, it's safe to call it again
+func (repo *ReviewRepo) EnsureIndexes() error {
+	return repo.storage.EnsureIndex(reviewIndex)
+}
+
+// NewReviewRepo sets ratings of restaurants through the accessor
+func NewReviewRepo(restaurants RestaurantAccessor) *ReviewRepo {
+	repo := &ReviewRepo{
+		storage:     storages.GetDataAccess(models.ReviewCollectionName),
+		restaurants: storages.GetDataAccess(models.RestaurantCollectionName),
+		ratings:     restaurants,
+	}
+	if err := repo.EnsureIndexes(); err != nil {
+		logging.Default().WithError(err).Fatalf("Error creating indexes of %s", models.ReviewCollectionName)
+	}
+
+	return repo
+}
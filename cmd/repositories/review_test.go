@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"venues/cmd/models"
+	"venues/pkg/logging"
+	"venues/pkg/mongo"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// racingRestaurants changes the restaurant right before the first changes of it, as concurrent requests would,
+// every change fails with err when it's set
+type racingRestaurants struct {
+	*mongo.MemoryDataAccess
+
+	races int
+	err   error
+}
+
+func (da *racingRestaurants) Update(ctx context.Context, query interface{}, object interface{}) error {
+	if da.err != nil {
+		return da.err
+	}
+	if da.races > 0 {
+		da.races--
+		da.MemoryDataAccess.Update(ctx, bson.M{"_id": query.(bson.M)["_id"]}, bson.M{"$inc": bson.M{"version": 1}})
+	}
+
+	return da.MemoryDataAccess.Update(ctx, query, object)
+}
+
+type ReviewRepoTestSuite struct {
+	suite.Suite
+
+	storage     *mongo.MemoryDataAccess
+	restaurants *mongo.MemoryDataAccess
+	repo        *ReviewRepo
+	restaurant  *models.Restaurant
+}
+
+func (suite *ReviewRepoTestSuite) SetupTest() {
+	suite.storage = mongo.NewMemoryDataAccess()
+	suite.restaurants = mongo.NewMemoryDataAccess()
+	suite.repo = &ReviewRepo{storage: suite.storage, restaurants: suite.restaurants, ratings: &RestaurantRepo{storage: suite.restaurants}}
+	if err := suite.repo.EnsureIndexes(); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	suite.restaurant = &models.Restaurant{ID: bson.NewObjectId(), Name: "Name", City: "Moscow"}
+	if err := suite.restaurants.Insert(ctx, suite.restaurant); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+}
+
+func (suite *ReviewRepoTestSuite) rating() *models.Rating {
+	result := &models.Restaurant{}
+	if err := suite.restaurants.Find(ctx, bson.M{"_id": suite.restaurant.ID}).One(result); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	return result.Rating
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateSuccess() {
+	review := &models.Review{Author: "Ann", Score: 10, Text: "Tasty"}
+	err := suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, review)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(review.ID.Valid())
+	suite.Assertions.Equal(review.RestaurantID, suite.restaurant.ID)
+	suite.Assertions.False(review.CreatedAt.IsZero())
+	suite.Assertions.Equal(review.UpdatedAt, review.CreatedAt)
+
+	result := &models.Review{}
+	suite.storage.Find(ctx, bson.M{"_id": review.ID}).One(result)
+	suite.Assertions.Equal(result, review)
+
+	suite.Assertions.Equal(suite.rating(), &models.Rating{Count: 1, Average: 10, Score: 5.83})
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateUpdatesRating() {
+	for _, score := range []int{10, 8, 3} {
+		err := suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Review{Author: "Ann", Score: score})
+		suite.Assertions.Nil(err)
+	}
+
+	// (5 * 5 + 21) / (5 + 3)
+	suite.Assertions.Equal(suite.rating(), &models.Rating{Count: 3, Average: 7, Score: 5.75})
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateRatingRace() {
+	racing := &racingRestaurants{MemoryDataAccess: suite.restaurants, races: ratingAttempts - 1}
+	suite.repo.restaurants, suite.repo.ratings = racing, &RestaurantRepo{storage: racing}
+
+	err := suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Review{Author: "Ann", Score: 10})
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(suite.rating(), &models.Rating{Count: 1, Average: 10, Score: 5.83})
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateRatingFail() {
+	racing := &racingRestaurants{MemoryDataAccess: suite.restaurants, err: errors.New("mocked error")}
+	suite.repo.restaurants, suite.repo.ratings = racing, &RestaurantRepo{storage: racing}
+	review := &models.Review{Author: "Ann", Score: 10}
+	output := &bytes.Buffer{}
+	requestCtx := logging.WithContext(ctx, logging.New(output, logging.InfoLevel).With("request_id", "request"))
+
+	err := suite.repo.Create(requestCtx, &models.Restaurant{ID: suite.restaurant.ID}, review)
+
+	// the review is stored, so it isn't reported as failed
+	suite.Assertions.Nil(err)
+	count, _ := suite.storage.Find(ctx, bson.M{"_id": review.ID}).Count()
+	suite.Assertions.Equal(count, 1)
+	suite.Assertions.Nil(suite.rating())
+	// the failure is logged by the logger of the request
+	entry := map[string]interface{}{}
+	suite.Assertions.Nil(json.Unmarshal(output.Bytes(), &entry))
+	suite.Assertions.Equal(entry["message"], "Error updating the rating")
+	suite.Assertions.Equal(entry["request_id"], "request")
+	suite.Assertions.Equal(entry["restaurant_id"], suite.restaurant.ID.Hex())
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateNotFound() {
+	err := suite.repo.Create(ctx, &models.Restaurant{ID: bson.NewObjectId()}, &models.Review{Author: "Ann", Score: 10})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+	count, _ := suite.storage.Find(ctx, nil).Count()
+	suite.Assertions.Zero(count)
+}
+
+func (suite *ReviewRepoTestSuite) TestList() {
+	other := &models.Restaurant{ID: bson.NewObjectId(), Name: "Other"}
+	suite.restaurants.Insert(ctx, other)
+	suite.repo.Create(ctx, other, &models.Review{Author: "Bob", Score: 1})
+
+	for _, author := range []string{"First", "Second", "Third"} {
+		suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Review{Author: author, Score: 5})
+	}
+
+	result, err := suite.repo.List(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Pagination{Page: 1, PageSize: 2})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result.Total, 3)
+	suite.Assertions.Equal(result.NextPage, 2)
+	suite.Assertions.Equal(result.Items[0].Author, "Third")
+	suite.Assertions.Equal(result.Items[1].Author, "Second")
+
+	result, err = suite.repo.List(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Pagination{Page: 2, PageSize: 2})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result.Items, 1)
+	suite.Assertions.Equal(result.Items[0].Author, "First")
+	suite.Assertions.Zero(result.NextPage)
+}
+
+func (suite *ReviewRepoTestSuite) TestListNotFound() {
+	_, err := suite.repo.List(ctx, &models.Restaurant{ID: bson.NewObjectId()}, firstPage())
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *ReviewRepoTestSuite) TestCreateNotFoundInTrash() {
+	suite.restaurants.Update(ctx, bson.M{"_id": suite.restaurant.ID}, bson.M{"$set": bson.M{"deleted_at": time.Now()}})
+
+	err := suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Review{Author: "Ann", Score: 10})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *ReviewRepoTestSuite) TestPurge() {
+	purged := bson.NewObjectId()
+	suite.storage.Insert(ctx, &models.Review{ID: bson.NewObjectId(), RestaurantID: purged, Author: "Ann", Score: 1})
+	suite.repo.Create(ctx, &models.Restaurant{ID: suite.restaurant.ID}, &models.Review{Author: "Bob", Score: 9})
+
+	err := suite.repo.Purge(ctx, []bson.ObjectId{purged, suite.restaurant.ID})
+	suite.Assertions.Nil(err)
+
+	reviews := []models.Review{}
+	suite.storage.Find(ctx, nil).All(&reviews)
+	suite.Assertions.Len(reviews, 1)
+	suite.Assertions.Equal(reviews[0].Author, "Bob")
+}
+
+func TestReviewRepoTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewRepoTestSuite))
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"strings"
+
+	"venues/cmd/models"
+	"venues/pkg/mongo"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// textIndex covers names, cities and dish names,
+// a match by name is worth more than a match by a dish
+var textIndex = mgo.Index{
+	Key:     []string{"$text:name", "$text:city", "$text:menu.name"},
+	Weights: map[string]int{"name": 10, "city": 5, "menu.name": 2},
+	Name:    "restaurants_text",
+}
+
+type scoredRestaurant struct {
+	models.Restaurant `bson:",inline"`
+	Score             float64 `bson:"score"`
+}
+
+// Search orders restaurants by relevance, the most relevant go first
+func (repo *RestaurantRepo) Search(ctx context.Context, text string, pagination *models.Pagination) (*models.SearchPage, error) {
+	query := bson.M{"$text": bson.M{"$search": text}, "deleted_at": bson.M{"$exists": false}}
+
+	total, err := repo.storage.Find(ctx, query).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	pageNumber := pagination.Page
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	var found []scoredRestaurant
+	err = repo.storage.Find(ctx, query).
+		Select(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		Sort("$textScore:score", "_id").
+		Skip(pagination.PageSize * (pageNumber - 1)).
+		Limit(pagination.PageSize + 1).
+		All(&found)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.SearchPage{Items: []models.SearchResult{}, Total: total, PageSize: pagination.PageSize}
+	if len(found) > pagination.PageSize {
+		found = found[:pagination.PageSize]
+		page.NextPage = pageNumber + 1
+	}
+
+	terms := map[string]bool{}
+	for _, term := range mongo.TextTerms(text) {
+		terms[term] = true
+	}
+
+	for i := range found {
+		restaurant := &found[i].Restaurant
+		result := models.SearchResult{Restaurant: restaurant, Score: found[i].Score, Dishes: []models.DishMatch{}}
+		for _, dish := range restaurant.Menu {
+			if highlight, ok := highlightTerms(dish.Name, terms); ok {
+				result.Dishes = append(result.Dishes, models.DishMatch{Dish: dish, Highlight: highlight})
+			}
+		}
+		restaurant.Menu = nil
+
+		page.Items = append(page.Items, result)
+	}
+
+	return page, nil
+}
+
+// highlightTerms wraps words of the text that are search terms in <em> tags,
+// the rest is escaped, so the result is safe to render as html
+func highlightTerms(text string, terms map[string]bool) (string, bool) {
+	var result bytes.Buffer
+	matched := false
+	last := 0
+	for _, word := range mongo.TextWords(text) {
+		if !terms[strings.ToLower(text[word[0]:word[1]])] {
+			continue
+		}
+
+		matched = true
+		result.WriteString(html.EscapeString(text[last:word[0]]))
+		result.WriteString("<em>")
+		result.WriteString(html.EscapeString(text[word[0]:word[1]]))
+		result.WriteString("</em>")
+		last = word[1]
+	}
+	result.WriteString(html.EscapeString(text[last:]))
+
+	return result.String(), matched
+}
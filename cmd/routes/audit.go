@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"venues/cmd/controllers"
+
+	"github.com/labstack/echo"
+)
+
+func BuildAuditGroup(group *echo.Group) {
+	controller := controllers.NewAuditController()
+
+	group.GET("", controller.List)
+}
@@ -2,16 +2,38 @@ package routes
 
 import (
 	"venues/cmd/controllers"
+	"venues/cmd/repositories"
+	"venues/pkg/pathparams"
 
 	"github.com/labstack/echo"
 )
 
 func BuildRestaurantGroup(group *echo.Group) {
 	controller := controllers.NewRestaurantController()
+	// changes are recorded in the audit log on behalf of the principal and the request of the context
+	controller.Repo = repositories.NewAuditedRestaurantRepo(controller.Repo, repositories.NewAuditRepo())
+	reviewController := controllers.NewReviewController(controller.Repo)
+	group.Use(pathparams.ObjectIDs("restaurant_id", "dish_id"))
+
 	group.GET("", controller.List)
 	group.POST("", controller.Create)
-	group.POST("/:restaurant_id", controller.Update)
+	group.GET("/search", controller.Search)
+	group.GET("/trash", controller.Trash)
+	group.GET("/:restaurant_id", controller.Get)
+	group.PUT("/:restaurant_id", controller.Update)
+	group.PATCH("/:restaurant_id", controller.Patch)
+	// updating with POST is kept for old clients, it's a patch
+	group.POST("/:restaurant_id", controller.Patch)
 	group.DELETE("/:restaurant_id", controller.Remove)
+	group.POST("/:restaurant_id/restore", controller.Restore)
+	group.POST("/:restaurant_id/owners", controller.AddOwner)
+	group.DELETE("/:restaurant_id/owners/:owner_id", controller.RemoveOwner)
 	group.POST("/:restaurant_id/dish", controller.AddDish)
 	group.GET("/:restaurant_id/dish", controller.ListDish)
+	group.GET("/:restaurant_id/dish/:dish_id", controller.GetDish)
+	group.PUT("/:restaurant_id/dish/:dish_id", controller.UpdateDish)
+	group.PATCH("/:restaurant_id/dish/:dish_id", controller.PatchDish)
+	group.DELETE("/:restaurant_id/dish/:dish_id", controller.RemoveDish)
+	group.POST("/:restaurant_id/reviews", reviewController.Create)
+	group.GET("/:restaurant_id/reviews", reviewController.List)
 }
@@ -1,16 +1,18 @@
 package settings
 
 import (
-	"fmt"
 	"os"
+	"strconv"
+	"time"
+
+	"venues/pkg/logging"
 
 	"github.com/joho/godotenv"
-	"github.com/labstack/gommon/log"
 )
 
 func Load() {
 	if err := godotenv.Load(os.ExpandEnv("$GOPATH/src/venues/.env")); err != nil {
-		log.Warn("Error loading .env file")
+		logging.Default().WithError(err).Warn("Error loading .env file")
 	}
 }
 
@@ -18,8 +20,59 @@ func MustGetSetting(key string) string {
 	value := os.Getenv(key)
 
 	if value == "" {
-		log.Fatal(fmt.Sprintf("Error retreiving \"%s\"", key))
+		logging.Default().Fatalf("Error retreiving \"%s\"", key)
 	}
 
 	return value
 }
+
+func GetSetting(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
+
+func GetIntSetting(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		logging.Default().Fatalf("Error parsing \"%s\" as integer", key)
+	}
+
+	return number
+}
+
+func GetBoolSetting(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		logging.Default().Fatalf("Error parsing \"%s\" as boolean", key)
+	}
+
+	return flag
+}
+
+// GetDurationSetting parses durations like "5s" or "1m30s"
+func GetDurationSetting(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		logging.Default().Fatalf("Error parsing \"%s\" as duration", key)
+	}
+
+	return duration
+}
@@ -2,21 +2,47 @@ package storages
 
 import (
 	"venues/cmd/settings"
+	"venues/pkg/logging"
+	"venues/pkg/metrics"
+	"venues/pkg/mongo"
 
 	"gopkg.in/mgo.v2"
 
-	"log"
+	"sync"
+	"time"
+)
+
+const (
+	MongoStorage  = "mongo"
+	MemoryStorage = "memory"
 )
 
 var storage *mgo.Database
 
+// storageMetrics measure every data accessor, so every repository is measured, and traced as well
+var storageMetrics = mongo.NewStorageMetrics(metrics.Default())
+
+// defaultStorageTimeout is how long an operation may take unless STORAGE_TIMEOUT says otherwise,
+// requests don't wait for mongo forever
+const defaultStorageTimeout = 10 * time.Second
+
+var (
+	timeoutsOnce sync.Once
+	timeouts     mongo.Timeouts
+)
+
+var (
+	memoryMutex       sync.Mutex
+	memoryCollections = map[string]*mongo.MemoryDataAccess{}
+)
+
 func initStorage(db string) *mgo.Database {
 	dialUrl := settings.MustGetSetting("MONGO_ADDRESS")
 
 	if storage == nil {
 		session, err := mgo.Dial(dialUrl)
 		if err != nil {
-			log.Fatal("Error initializing Storage \n %s", err.Error())
+			logging.Default().WithError(err).Fatal("Error initializing storage")
 		}
 
 		storage = session.DB(db)
@@ -32,3 +58,70 @@ func GetStorage() *mgo.Database {
 func GetTestStorage() *mgo.Database {
 	return initStorage(settings.MustGetSetting("MONGO_DB_NAME_TEST"))
 }
+
+// StorageType returns the configured backend, mongo is used unless
+// STORAGE=memory is set, which lets the app run without mongod
+func StorageType() string {
+	return settings.GetSetting("STORAGE", MongoStorage)
+}
+
+func GetDataAccess(collection string) mongo.DataAccessor {
+	var dataAccess mongo.DataAccessor
+	if StorageType() == MemoryStorage {
+		dataAccess = getMemoryDataAccess(collection)
+	} else {
+		dataAccess = &mongo.DataAccess{Collection: GetStorage().C(collection)}
+	}
+
+	dataAccess = &mongo.InstrumentedDataAccess{DataAccessor: dataAccess, Collection: collection, Metrics: storageMetrics}
+	dataAccess = &mongo.TracedDataAccess{DataAccessor: dataAccess, Collection: collection}
+	// the timeout is outermost, so spans and metrics see operations stopped by it
+	return &mongo.TimeoutDataAccess{DataAccessor: dataAccess, Timeouts: storageTimeouts()}
+}
+
+// storageTimeouts are read once, STORAGE_TIMEOUT limits every operation,
+// STORAGE_TIMEOUTS limits particular ones like "find=2s; remove_all=1m", 0 doesn't limit it
+func storageTimeouts() mongo.Timeouts {
+	timeoutsOnce.Do(func() {
+		operations, err := mongo.ParseTimeouts(settings.GetSetting("STORAGE_TIMEOUTS", ""))
+		if err != nil {
+			logging.Default().WithError(err).Fatal("Error parsing STORAGE_TIMEOUTS")
+		}
+
+		timeouts = mongo.Timeouts{
+			Default:    settings.GetDurationSetting("STORAGE_TIMEOUT", defaultStorageTimeout),
+			Operations: operations,
+		}
+	})
+
+	return timeouts
+}
+
+func getMemoryDataAccess(collection string) *mongo.MemoryDataAccess {
+	memoryMutex.Lock()
+	defer memoryMutex.Unlock()
+
+	dataAccess, ok := memoryCollections[collection]
+	if !ok {
+		dataAccess = mongo.NewMemoryDataAccess()
+		memoryCollections[collection] = dataAccess
+	}
+
+	return dataAccess
+}
+
+func Ping() error {
+	if StorageType() == MemoryStorage {
+		return nil
+	}
+
+	return GetStorage().Session.Ping()
+}
+
+func Close() {
+	if StorageType() == MemoryStorage {
+		return
+	}
+
+	GetStorage().Session.Close()
+}
@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
+	"os"
+
 	"venues/cmd/assembly"
+	"venues/cmd/cli"
+	"venues/cmd/repositories"
 	"venues/cmd/settings"
+	"venues/pkg/logging"
 )
 
 func main() {
 	settings.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == cli.APIKeyCommand {
+		if err := cli.APIKeys(context.Background(), repositories.NewAPIKeyRepo(), os.Args[2:], os.Stdout); err != nil {
+			logging.Default().Fatal(err.Error())
+		}
+		return
+	}
+
 	app := assembly.NewApp()
 	port := settings.MustGetSetting("PORT")
 	app.Run(port)
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+
+	MethodAPIKey = "api_key"
+
+	apiKeySize = 32
+)
+
+// APIKeyStore finds the principal of a key by its hash, see HashAPIKey,
+// it returns nil principal and nil error for an unknown key
+type APIKeyStore interface {
+	Lookup(ctx context.Context, hash string) (*Principal, error)
+}
+
+// APIKeyAuthenticator reads the key from X-API-Key header
+type APIKeyAuthenticator struct {
+	Store APIKeyStore
+}
+
+func (authenticator *APIKeyAuthenticator) Authenticate(request *http.Request) (*Principal, error) {
+	key := request.Header.Get(HeaderAPIKey)
+	if key == "" {
+		return nil, nil
+	}
+
+	principal, err := authenticator.Store.Lookup(request.Context(), HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if principal == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	principal.Method = MethodAPIKey
+	return principal, nil
+}
+
+// NewAPIKey generates a random key, only its hash has to be stored
+func NewAPIKey() (string, error) {
+	key := make([]byte, apiKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+// HashAPIKey is a plain SHA-256, keys are random enough not to be guessed from it
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
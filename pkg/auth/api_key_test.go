@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type mapKeyStore map[string]string
+
+func (store mapKeyStore) Lookup(ctx context.Context, hash string) (*Principal, error) {
+	if name, ok := store[hash]; ok {
+		return &Principal{Subject: name}, nil
+	}
+
+	return nil, nil
+}
+
+type APIKeyTestSuite struct {
+	suite.Suite
+}
+
+func (suite *APIKeyTestSuite) TestAuthenticate() {
+	key, err := NewAPIKey()
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(key, 43)
+
+	authenticator := &APIKeyAuthenticator{Store: mapKeyStore{HashAPIKey(key): "deploy"}}
+
+	for header, expected := range map[string]struct {
+		principal *Principal
+		err       error
+	}{
+		"":      {nil, nil},
+		key:     {&Principal{Subject: "deploy", Method: MethodAPIKey}, nil},
+		"wrong": {nil, ErrInvalidCredentials},
+	} {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set(HeaderAPIKey, header)
+
+		principal, err := authenticator.Authenticate(request)
+		suite.Assertions.Equal(principal, expected.principal, header)
+		suite.Assertions.Equal(err, expected.err, header)
+	}
+}
+
+func (suite *APIKeyTestSuite) TestNewAPIKeyIsRandom() {
+	first, _ := NewAPIKey()
+	second, _ := NewAPIKey()
+
+	suite.Assertions.NotEqual(first, second)
+	suite.Assertions.NotEqual(HashAPIKey(first), HashAPIKey(second))
+	suite.Assertions.Equal(HashAPIKey(first), HashAPIKey(first))
+}
+
+func TestAPIKeyTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyTestSuite))
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"venues/pkg/httperrors"
+	"venues/pkg/logging"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+const principalContextKey = "auth.principal"
+
+const (
+	errCredentialsMsg = "Invalid credentials"
+	errRequiredMsg    = "Authentication is required"
+	errUnavailableMsg = "Authentication is unavailable"
+)
+
+// ErrInvalidCredentials is returned by authenticators for credentials that are present but wrong
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// roles of principals, a principal without a role is a viewer
+const (
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+	RoleViewer = "viewer"
+)
+
+// Principal is who made the request
+type Principal struct {
+	Subject string `json:"subject"`
+	// Method is the way the principal was authenticated, "api_key" or "jwt"
+	Method string `json:"method"`
+	Role   string `json:"role"`
+}
+
+// ID tells principals authenticated in different ways apart, like "jwt:ann"
+func (principal *Principal) ID() string {
+	return principal.Method + ":" + principal.Subject
+}
+
+// IsRole tells if the role is known, an empty role is a viewer
+func IsRole(role string) bool {
+	return role == "" || role == RoleAdmin || role == RoleOwner || role == RoleViewer
+}
+
+// Authenticator tells who made the request from its credentials,
+// it returns nil principal and nil error when the request has no credentials of its kind
+type Authenticator interface {
+	Authenticate(*http.Request) (*Principal, error)
+}
+
+type Config struct {
+	Skipper middleware.Skipper
+	// Authenticators are tried in order, the first one finding credentials decides
+	Authenticators []Authenticator
+	// PublicReads lets GET, HEAD and OPTIONS requests without credentials through
+	PublicReads bool
+}
+
+// Middleware stores the principal of the request on the context, see PrincipalOf,
+// and puts it on the context of the request, see PrincipalFromContext.
+// Requests with invalid credentials are rejected even if they could be made without them.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if config.Skipper(context) {
+				return next(context)
+			}
+
+			for _, authenticator := range config.Authenticators {
+				principal, err := authenticator.Authenticate(context.Request())
+				if err == ErrInvalidCredentials {
+					return unauthorized(context, errCredentialsMsg)
+				}
+				if err != nil {
+					logging.From(context).WithError(err).Error("Error authenticating")
+					return httperrors.New(http.StatusServiceUnavailable, errUnavailableMsg)
+				}
+
+				if principal != nil {
+					SetPrincipal(context, principal)
+					return next(context)
+				}
+			}
+
+			if config.PublicReads && isRead(context.Request().Method) {
+				return next(context)
+			}
+
+			return unauthorized(context, errRequiredMsg)
+		}
+	}
+}
+
+// PrincipalOf returns the principal stored by Middleware, nil for anonymous requests
+func PrincipalOf(context echo.Context) *Principal {
+	principal, _ := context.Get(principalContextKey).(*Principal)
+	return principal
+}
+
+// SetPrincipal stores the principal the way Middleware does
+func SetPrincipal(context echo.Context, principal *Principal) {
+	context.Set(principalContextKey, principal)
+	request := context.Request()
+	context.SetRequest(request.WithContext(WithPrincipal(request.Context(), principal)))
+}
+
+func unauthorized(context echo.Context, message string) error {
+	context.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer, ApiKey")
+	return httperrors.New(http.StatusUnauthorized, message)
+}
+
+func isRead(method string) bool {
+	return method == echo.GET || method == echo.HEAD || method == echo.OPTIONS
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+func (m *MockAuthenticator) Authenticate(request *http.Request) (*Principal, error) {
+	args := m.Called(request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Principal), args.Error(1)
+}
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	first     *MockAuthenticator
+	second    *MockAuthenticator
+	principal *Principal
+	recorder  *httptest.ResponseRecorder
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.first = &MockAuthenticator{}
+	suite.second = &MockAuthenticator{}
+	suite.principal = nil
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *MiddlewareTestSuite) serve(config Config, method string) int {
+	config.Authenticators = []Authenticator{suite.first, suite.second}
+	context := echo.New().NewContext(httptest.NewRequest(method, "/", nil), suite.recorder)
+
+	handler := Middleware(config)(func(context echo.Context) error {
+		suite.principal = PrincipalOf(context)
+		suite.Assertions.Equal(PrincipalFromContext(context.Request().Context()), suite.principal)
+		return context.NoContent(http.StatusOK)
+	})
+	if err := handler(context); err != nil {
+		httperrors.Handler(err, context)
+	}
+
+	return context.Response().Status
+}
+
+func (suite *MiddlewareTestSuite) TestAuthenticated() {
+	principal := &Principal{Subject: "ann", Method: MethodJWT}
+	suite.first.On("Authenticate", mock.Anything).Return(nil, nil)
+	suite.second.On("Authenticate", mock.Anything).Return(principal, nil)
+
+	status := suite.serve(Config{}, echo.POST)
+
+	suite.Assertions.Equal(status, http.StatusOK)
+	suite.Assertions.Equal(suite.principal, principal)
+}
+
+func (suite *MiddlewareTestSuite) TestInvalidCredentials() {
+	suite.first.On("Authenticate", mock.Anything).Return(nil, ErrInvalidCredentials)
+
+	status := suite.serve(Config{PublicReads: true}, echo.GET)
+
+	suite.second.AssertNotCalled(suite.T(), "Authenticate", mock.Anything)
+	suite.Assertions.Equal(status, http.StatusUnauthorized)
+	suite.Assertions.Equal(suite.recorder.Header().Get(echo.HeaderWWWAuthenticate), "Bearer, ApiKey")
+}
+
+func (suite *MiddlewareTestSuite) TestUnavailable() {
+	suite.first.On("Authenticate", mock.Anything).Return(nil, errors.New("mocked error"))
+
+	status := suite.serve(Config{}, echo.POST)
+
+	suite.Assertions.Equal(status, http.StatusServiceUnavailable)
+}
+
+func (suite *MiddlewareTestSuite) TestAnonymous() {
+	for _, test := range []struct {
+		method      string
+		publicReads bool
+		status      int
+	}{
+		{echo.GET, true, http.StatusOK},
+		{echo.HEAD, true, http.StatusOK},
+		{echo.POST, true, http.StatusUnauthorized},
+		{echo.DELETE, true, http.StatusUnauthorized},
+		{echo.GET, false, http.StatusUnauthorized},
+	} {
+		suite.SetupTest()
+		suite.first.On("Authenticate", mock.Anything).Return(nil, nil)
+		suite.second.On("Authenticate", mock.Anything).Return(nil, nil)
+
+		status := suite.serve(Config{PublicReads: test.publicReads}, test.method)
+
+		suite.Assertions.Equal(status, test.status, test.method)
+		suite.Assertions.Nil(suite.principal)
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestSkipper() {
+	status := suite.serve(Config{Skipper: func(echo.Context) bool { return true }}, echo.POST)
+
+	suite.first.AssertNotCalled(suite.T(), "Authenticate", mock.Anything)
+	suite.Assertions.Equal(status, http.StatusOK)
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
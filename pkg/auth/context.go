@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"context"
+)
+
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal is a copy of the context carrying the principal, see PrincipalFromContext
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal Middleware put on the context of the request, nil for anonymous requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey).(*Principal)
+	return principal
+}
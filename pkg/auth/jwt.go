@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+const (
+	MethodJWT = "jwt"
+
+	bearerPrefix = "Bearer "
+)
+
+// JWTAuthenticator reads the token from "Authorization: Bearer" header,
+// tokens are signed with HS256 by HMACKey or with RS256 by the private key of RSAKey,
+// an algorithm without its key configured is rejected
+type JWTAuthenticator struct {
+	HMACKey []byte
+	RSAKey  *rsa.PublicKey
+	// Issuer is checked against "iss" claim when it's set
+	Issuer string
+}
+
+func (authenticator *JWTAuthenticator) Authenticate(request *http.Request) (*Principal, error) {
+	header := request.Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return nil, nil
+	}
+
+	// the parser accepts any algorithm without valid methods
+	methods := authenticator.methods()
+	if len(methods) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	claims := roleClaims{}
+	parser := &jwt.Parser{ValidMethods: methods}
+	_, err := parser.ParseWithClaims(strings.TrimPrefix(header, bearerPrefix), &claims, authenticator.key)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if claims.Subject == "" || (authenticator.Issuer != "" && claims.Issuer != authenticator.Issuer) || !IsRole(claims.Role) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{Subject: claims.Subject, Method: MethodJWT, Role: claims.Role}, nil
+}
+
+// roleClaims have the role of the principal in "role" claim
+type roleClaims struct {
+	jwt.StandardClaims
+	Role string `json:"role,omitempty"`
+}
+
+func (authenticator *JWTAuthenticator) methods() []string {
+	var methods []string
+	if len(authenticator.HMACKey) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if authenticator.RSAKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	return methods
+}
+
+// key is chosen by the algorithm of the token, which is one of methods
+func (authenticator *JWTAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	if token.Method == jwt.SigningMethodRS256 {
+		return authenticator.RSAKey, nil
+	}
+
+	return authenticator.HMACKey, nil
+}
+
+// ParseRSAPublicKey reads a PEM encoded public key to check RS256 tokens
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(data)
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type JWTTestSuite struct {
+	suite.Suite
+
+	secret     []byte
+	privateKey *rsa.PrivateKey
+}
+
+func (suite *JWTTestSuite) SetupSuite() {
+	suite.secret = []byte("secret")
+
+	var err error
+	if suite.privateKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+}
+
+func (suite *JWTTestSuite) sign(method jwt.SigningMethod, key interface{}, claims jwt.StandardClaims) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	return token
+}
+
+func (suite *JWTTestSuite) authenticate(authenticator *JWTAuthenticator, header string) (*Principal, error) {
+	request := httptest.NewRequest(echo.GET, "/", nil)
+	request.Header.Set(echo.HeaderAuthorization, header)
+	return authenticator.Authenticate(request)
+}
+
+func (suite *JWTTestSuite) TestAuthenticate() {
+	authenticator := &JWTAuthenticator{HMACKey: suite.secret, RSAKey: &suite.privateKey.PublicKey}
+	claims := jwt.StandardClaims{Subject: "ann", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	for _, token := range []string{
+		suite.sign(jwt.SigningMethodHS256, suite.secret, claims),
+		suite.sign(jwt.SigningMethodRS256, suite.privateKey, claims),
+	} {
+		principal, err := suite.authenticate(authenticator, "Bearer "+token)
+
+		suite.Assertions.Nil(err)
+		suite.Assertions.Equal(principal, &Principal{Subject: "ann", Method: MethodJWT})
+	}
+}
+
+func (suite *JWTTestSuite) TestNoToken() {
+	authenticator := &JWTAuthenticator{HMACKey: suite.secret}
+
+	for _, header := range []string{"", "Basic YW5uOnNlY3JldA=="} {
+		principal, err := suite.authenticate(authenticator, header)
+
+		suite.Assertions.Nil(principal, header)
+		suite.Assertions.Nil(err, header)
+	}
+}
+
+func (suite *JWTTestSuite) TestInvalidToken() {
+	claims := jwt.StandardClaims{Subject: "ann"}
+	hs256 := suite.sign(jwt.SigningMethodHS256, suite.secret, claims)
+
+	for name, test := range map[string]struct {
+		authenticator *JWTAuthenticator
+		token         string
+	}{
+		"malformed":     {&JWTAuthenticator{HMACKey: suite.secret}, "abc"},
+		"wrong secret":  {&JWTAuthenticator{HMACKey: []byte("other")}, hs256},
+		"no keys":       {&JWTAuthenticator{}, suite.sign(jwt.SigningMethodHS256, []byte{}, claims)},
+		"no rsa key":    {&JWTAuthenticator{HMACKey: suite.secret}, suite.sign(jwt.SigningMethodRS256, suite.privateKey, claims)},
+		"no hmac key":   {&JWTAuthenticator{RSAKey: &suite.privateKey.PublicKey}, hs256},
+		"none":          {&JWTAuthenticator{HMACKey: suite.secret}, suite.sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims)},
+		"wrong issuer":  {&JWTAuthenticator{HMACKey: suite.secret, Issuer: "venues"}, hs256},
+		"no subject":    {&JWTAuthenticator{HMACKey: suite.secret}, suite.sign(jwt.SigningMethodHS256, suite.secret, jwt.StandardClaims{})},
+		"expired token": {&JWTAuthenticator{HMACKey: suite.secret}, suite.sign(jwt.SigningMethodHS256, suite.secret, jwt.StandardClaims{Subject: "ann", ExpiresAt: time.Now().Add(-time.Minute).Unix()})},
+	} {
+		principal, err := suite.authenticate(test.authenticator, "Bearer "+test.token)
+
+		suite.Assertions.Nil(principal, name)
+		suite.Assertions.Equal(err, ErrInvalidCredentials, name)
+	}
+}
+
+func (suite *JWTTestSuite) TestRole() {
+	authenticator := &JWTAuthenticator{HMACKey: suite.secret}
+
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, roleClaims{jwt.StandardClaims{Subject: "ann"}, RoleAdmin}).SignedString(suite.secret)
+	principal, err := suite.authenticate(authenticator, "Bearer "+token)
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(principal.Role, RoleAdmin)
+
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, roleClaims{jwt.StandardClaims{Subject: "ann"}, "root"}).SignedString(suite.secret)
+	_, err = suite.authenticate(authenticator, "Bearer "+token)
+	suite.Assertions.Equal(err, ErrInvalidCredentials)
+}
+
+func (suite *JWTTestSuite) TestIssuer() {
+	authenticator := &JWTAuthenticator{HMACKey: suite.secret, Issuer: "venues"}
+	token := suite.sign(jwt.SigningMethodHS256, suite.secret, jwt.StandardClaims{Subject: "ann", Issuer: "venues"})
+
+	principal, err := suite.authenticate(authenticator, "Bearer "+token)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(principal.Subject, "ann")
+}
+
+func TestJWTTestSuite(t *testing.T) {
+	suite.Run(t, new(JWTTestSuite))
+}
@@ -0,0 +1,124 @@
+package healthcheckers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// statuses of checkers and of the whole report
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// Check is a checker as it's run, NonCritical checkers failing degrade the report instead of taking it down,
+// a zero Timeout is the timeout of the runner
+type Check struct {
+	Checker     Checker
+	NonCritical bool
+	Timeout     time.Duration
+}
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    string    `json:"status"`
+	CheckedAt time.Time `json:"checked_at"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Runner runs checks concurrently, a check that doesn't finish in its timeout is down,
+// the report is kept for CacheFor, so frequent probes don't hit backends on every request
+type Runner struct {
+	Checks   []Check
+	Timeout  time.Duration
+	CacheFor time.Duration
+	// Now is time.Now unless it's set
+	Now func() time.Time
+
+	mutex  sync.Mutex
+	report *Report
+}
+
+func (runner *Runner) now() time.Time {
+	if runner.Now != nil {
+		return runner.Now()
+	}
+
+	return time.Now()
+}
+
+// Run returns the cached report while it's fresh, otherwise it runs the checks,
+// probes coming while the checks run wait for their report instead of running them again
+func (runner *Runner) Run() *Report {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	if runner.report != nil && runner.now().Sub(runner.report.CheckedAt) < runner.CacheFor {
+		return runner.report
+	}
+
+	report := &Report{Status: StatusUp, CheckedAt: runner.now(), Checks: make([]Result, len(runner.Checks))}
+	var wait sync.WaitGroup
+	for i, check := range runner.Checks {
+		wait.Add(1)
+		go func(i int, check Check) {
+			defer wait.Done()
+			report.Checks[i] = runner.run(check)
+		}(i, check)
+	}
+	wait.Wait()
+
+	for _, result := range report.Checks {
+		switch {
+		case result.Status == StatusUp:
+		case result.Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+
+	runner.report = report
+	return report
+}
+
+// run gives up on a checker after its timeout, the checker can't be stopped,
+// so it's left to finish on its own
+func (runner *Runner) run(check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = runner.Timeout
+	}
+
+	result := Result{Name: check.Checker.Message(), Status: StatusUp, Critical: !check.NonCritical}
+	start := time.Now()
+	// the buffer lets a checker that is given up on finish without a reader
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Checker.Check()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-time.After(timeout):
+		err = fmt.Errorf("no answer in %s", timeout)
+	}
+
+	result.LatencyMS = float64(time.Since(start)) / float64(time.Millisecond)
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,114 @@
+package healthcheckers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// countingChecker answers with err after delay and counts how many times it's checked
+type countingChecker struct {
+	name   string
+	delay  time.Duration
+	err    error
+	checks chan struct{}
+}
+
+func newCountingChecker(name string, delay time.Duration, err error) *countingChecker {
+	return &countingChecker{name: name, delay: delay, err: err, checks: make(chan struct{}, 10)}
+}
+
+func (checker *countingChecker) Message() string {
+	return checker.name
+}
+
+func (checker *countingChecker) Check() error {
+	checker.checks <- struct{}{}
+	time.Sleep(checker.delay)
+	return checker.err
+}
+
+type RunnerTestSuite struct {
+	suite.Suite
+
+	now time.Time
+}
+
+func (suite *RunnerTestSuite) SetupTest() {
+	suite.now = time.Date(2018, 3, 31, 12, 0, 0, 0, time.UTC)
+}
+
+func (suite *RunnerTestSuite) runner(checks ...Check) *Runner {
+	return &Runner{Checks: checks, Timeout: time.Second, CacheFor: 5 * time.Second, Now: func() time.Time { return suite.now }}
+}
+
+func (suite *RunnerTestSuite) TestUp() {
+	report := suite.runner(
+		Check{Checker: newCountingChecker("Mongo", 0, nil)},
+		Check{Checker: newCountingChecker("Cache", 0, nil), NonCritical: true},
+	).Run()
+
+	suite.Assertions.Equal(report.Status, StatusUp)
+	suite.Assertions.Equal(report.CheckedAt, suite.now)
+	suite.Assertions.Len(report.Checks, 2)
+	suite.Assertions.Equal(report.Checks[0].Name, "Mongo")
+	suite.Assertions.True(report.Checks[0].Critical)
+	suite.Assertions.Equal(report.Checks[1].Name, "Cache")
+	suite.Assertions.False(report.Checks[1].Critical)
+}
+
+func (suite *RunnerTestSuite) TestDegraded() {
+	report := suite.runner(
+		Check{Checker: newCountingChecker("Mongo", 0, nil)},
+		Check{Checker: newCountingChecker("Cache", 0, errors.New("mocked error")), NonCritical: true},
+	).Run()
+
+	suite.Assertions.Equal(report.Status, StatusDegraded)
+	suite.Assertions.Equal(report.Checks[1].Status, StatusDown)
+	suite.Assertions.Equal(report.Checks[1].Error, "mocked error")
+}
+
+func (suite *RunnerTestSuite) TestDown() {
+	report := suite.runner(
+		Check{Checker: newCountingChecker("Mongo", 0, errors.New("mocked error"))},
+		Check{Checker: newCountingChecker("Cache", 0, errors.New("mocked error")), NonCritical: true},
+	).Run()
+
+	suite.Assertions.Equal(report.Status, StatusDown)
+}
+
+func (suite *RunnerTestSuite) TestTimeout() {
+	start := time.Now()
+	report := suite.runner(
+		Check{Checker: newCountingChecker("Mongo", time.Second, nil), Timeout: 20 * time.Millisecond},
+		Check{Checker: newCountingChecker("Cache", time.Second, nil), Timeout: 20 * time.Millisecond},
+	).Run()
+
+	// checks run concurrently, both time out in about the time of one of them
+	suite.Assertions.True(time.Since(start) < 500*time.Millisecond)
+	suite.Assertions.Equal(report.Status, StatusDown)
+	suite.Assertions.Equal(report.Checks[0].Error, "no answer in 20ms")
+	suite.Assertions.True(report.Checks[0].LatencyMS >= 20)
+}
+
+func (suite *RunnerTestSuite) TestCache() {
+	checker := newCountingChecker("Mongo", 0, nil)
+	runner := suite.runner(Check{Checker: checker})
+
+	first := runner.Run()
+	suite.now = suite.now.Add(4 * time.Second)
+	cached := runner.Run()
+	suite.now = suite.now.Add(time.Second)
+	fresh := runner.Run()
+
+	suite.Assertions.True(first == cached)
+	suite.Assertions.False(first == fresh)
+	suite.Assertions.Equal(fresh.CheckedAt, suite.now)
+	suite.Assertions.Len(checker.checks, 2)
+}
+
+func TestRunner(t *testing.T) {
+	suite.Run(t, new(RunnerTestSuite))
+}
@@ -0,0 +1,122 @@
+package httperrors
+
+import (
+	"fmt"
+	"net/http"
+
+	"venues/pkg/logging"
+
+	"github.com/labstack/echo"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+var (
+	_ error = new(Error)
+)
+
+// Detail points to a particular field of the request that caused the error
+type Detail struct {
+	Field       string   `json:"field,omitempty"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Error is the only error shape clients get, it's rendered by Handler
+type Error struct {
+	Code      int      `json:"code"`
+	Message   string   `json:"message"`
+	Details   []Detail `json:"details"`
+	RequestID string   `json:"request_id"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code=%d, message=%s", e.Code, e.Message)
+}
+
+func New(code int, message string, details ...Detail) *Error {
+	if details == nil {
+		details = []Detail{}
+	}
+
+	return &Error{Code: code, Message: message, Details: details}
+}
+
+// BadRequest wraps errors of binding and validation,
+// validation errors are split into per field details
+func BadRequest(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case validator.ValidationErrors:
+		details := make([]Detail, 0, len(e))
+		for _, fieldError := range e {
+			details = append(details, Detail{Field: fieldError.Field(), Message: fieldMessage(fieldError)})
+		}
+		return New(http.StatusBadRequest, "Validation failed", details...)
+	case *echo.HTTPError:
+		return New(http.StatusBadRequest, fmt.Sprint(e.Message))
+	}
+
+	return New(http.StatusBadRequest, err.Error())
+}
+
+func fieldMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("should be at least %s", fieldError.Param())
+	case "max":
+		return fmt.Sprintf("should be at most %s", fieldError.Param())
+	case "gt":
+		return fmt.Sprintf("should be greater than %s", fieldError.Param())
+	case "eq":
+		return fmt.Sprintf("should be %s", fieldError.Param())
+	case "city":
+		return "is not a known city"
+	case "lnglat":
+		return "should be [longitude, latitude]"
+	}
+
+	return fmt.Sprintf("failed on \"%s\" validation", fieldError.Tag())
+}
+
+// Handler is echo.HTTPErrorHandler rendering every error as Error json
+func Handler(err error, context echo.Context) {
+	var httpError *Error
+	switch e := err.(type) {
+	case *Error:
+		httpError = e
+	case validator.ValidationErrors:
+		httpError = BadRequest(e)
+	case *echo.HTTPError:
+		httpError = New(e.Code, fmt.Sprint(e.Message))
+	default:
+		logging.From(context).WithError(err).Error("Unexpected error")
+		httpError = New(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+
+	if context.Response().Committed {
+		return
+	}
+
+	httpError.RequestID = requestID(context)
+
+	if context.Request().Method == echo.HEAD {
+		err = context.NoContent(httpError.Code)
+	} else {
+		err = context.JSON(httpError.Code, httpError)
+	}
+
+	if err != nil {
+		logging.From(context).WithError(err).Error("Error rendering error")
+	}
+}
+
+func requestID(context echo.Context) string {
+	if id := context.Response().Header().Get(echo.HeaderXRequestID); id != "" {
+		return id
+	}
+
+	return context.Request().Header.Get(echo.HeaderXRequestID)
+}
@@ -0,0 +1,101 @@
+package httperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type object struct {
+	Name  string `json:"name" validate:"required"`
+	Price int    `json:"price" validate:"min=100"`
+}
+
+type HandlerTestSuite struct {
+	suite.Suite
+
+	echoContext echo.Context
+	recorder    *httptest.ResponseRecorder
+}
+
+func (suite *HandlerTestSuite) SetupTest() {
+	req := httptest.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "request-id")
+	suite.recorder = httptest.NewRecorder()
+	suite.echoContext = echo.New().NewContext(req, suite.recorder)
+}
+
+func (suite *HandlerTestSuite) result() *Error {
+	result := &Error{}
+	json.NewDecoder(suite.recorder.Body).Decode(result)
+	return result
+}
+
+func (suite *HandlerTestSuite) TestError() {
+	Handler(New(http.StatusNotFound, "Not found"), suite.echoContext)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusNotFound)
+	suite.Assertions.Equal(suite.result(), &Error{
+		Code:      http.StatusNotFound,
+		Message:   "Not found",
+		Details:   []Detail{},
+		RequestID: "request-id",
+	})
+}
+
+func (suite *HandlerTestSuite) TestValidationErrors() {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string { return field.Tag.Get("json") })
+	err := validate.Struct(&object{Price: 1})
+
+	Handler(err, suite.echoContext)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusBadRequest)
+	suite.Assertions.Equal(suite.result().Details, []Detail{
+		{Field: "name", Message: "is required"},
+		{Field: "price", Message: "should be at least 100"},
+	})
+}
+
+func (suite *HandlerTestSuite) TestEchoHTTPError() {
+	Handler(echo.ErrMethodNotAllowed, suite.echoContext)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusMethodNotAllowed)
+	suite.Assertions.Equal(suite.result().Message, "Method Not Allowed")
+}
+
+func (suite *HandlerTestSuite) TestUnknownError() {
+	Handler(errors.New("unknown"), suite.echoContext)
+
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusInternalServerError)
+	suite.Assertions.Equal(suite.result().Message, http.StatusText(http.StatusInternalServerError))
+}
+
+func (suite *HandlerTestSuite) TestBadRequest() {
+	err := BadRequest(echo.NewHTTPError(http.StatusBadRequest, "Request body can't be empty"))
+
+	suite.Assertions.Equal(err.Code, http.StatusBadRequest)
+	suite.Assertions.Equal(err.Message, "Request body can't be empty")
+}
+
+func (suite *HandlerTestSuite) TestMiddleware() {
+	suite.echoContext.Echo().HTTPErrorHandler = Handler
+	err := New(http.StatusConflict, "Conflict")
+
+	result := Middleware()(func(echo.Context) error { return err })(suite.echoContext)
+
+	suite.Assertions.Nil(result)
+	suite.Assertions.Equal(suite.echoContext.Response().Status, http.StatusConflict)
+	suite.Assertions.Equal(ErrorOf(suite.echoContext), err)
+}
+
+func TestHandler(t *testing.T) {
+	suite.Run(t, new(HandlerTestSuite))
+}
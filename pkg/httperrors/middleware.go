@@ -0,0 +1,28 @@
+package httperrors
+
+import (
+	"github.com/labstack/echo"
+)
+
+const errorContextKey = "httperrors.error"
+
+// Middleware renders the error of the handler with the error handler of echo, so middleware running
+// around it, like logging or metrics, reads the status from the response, see ErrorOf for the error itself
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if err := next(context); err != nil {
+				context.Set(errorContextKey, err)
+				context.Error(err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// ErrorOf returns the error rendered by Middleware, nil when the handler succeeded
+func ErrorOf(context echo.Context) error {
+	err, _ := context.Get(errorContextKey).(error)
+	return err
+}
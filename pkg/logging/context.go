@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"context"
+)
+
+// contextKey keeps values of the package apart from values of other packages on a context
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// WithContext is a copy of the context carrying the logger, see FromContext
+func WithContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by the context, the default logger without it,
+// Middleware puts the logger of the request on the context of the request
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return logger
+	}
+
+	return Default()
+}
+
+// WithRequestID is a copy of the context carrying the id of the request, see RequestIDFromContext
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the id of the request set by Middleware, empty without it
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/gommon/log"
+)
+
+var _ echo.Logger = new(EchoLogger)
+
+// EchoLogger lets echo and its middleware log through the logger,
+// OFF level of echo is the error level, the logger can't be turned off
+type EchoLogger struct {
+	*Logger
+
+	prefix string
+}
+
+func NewEchoLogger(logger *Logger) *EchoLogger {
+	return &EchoLogger{Logger: logger}
+}
+
+func (logger *EchoLogger) Prefix() string {
+	return logger.prefix
+}
+
+func (logger *EchoLogger) SetPrefix(prefix string) {
+	logger.prefix = prefix
+}
+
+func (logger *EchoLogger) Level() log.Lvl {
+	switch logger.Logger.Level() {
+	case DebugLevel:
+		return log.DEBUG
+	case InfoLevel:
+		return log.INFO
+	case WarnLevel:
+		return log.WARN
+	}
+
+	return log.ERROR
+}
+
+func (logger *EchoLogger) SetLevel(level log.Lvl) {
+	switch level {
+	case log.DEBUG:
+		logger.Logger.SetLevel(DebugLevel)
+	case log.INFO:
+		logger.Logger.SetLevel(InfoLevel)
+	case log.WARN:
+		logger.Logger.SetLevel(WarnLevel)
+	default:
+		logger.Logger.SetLevel(ErrorLevel)
+	}
+}
+
+func (logger *EchoLogger) Output() io.Writer {
+	return logger.Logger.Output()
+}
+
+func (logger *EchoLogger) SetOutput(output io.Writer) {
+	logger.Logger.SetOutput(output)
+}
+
+func (logger *EchoLogger) Print(i ...interface{}) {
+	logger.Logger.Info(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Printf(format string, args ...interface{}) {
+	logger.Logger.Infof(format, args...)
+}
+
+func (logger *EchoLogger) Printj(j log.JSON) {
+	logger.Logger.Info(encode(j))
+}
+
+func (logger *EchoLogger) Debug(i ...interface{}) {
+	logger.Logger.Debug(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Debugj(j log.JSON) {
+	logger.Logger.Debug(encode(j))
+}
+
+func (logger *EchoLogger) Info(i ...interface{}) {
+	logger.Logger.Info(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Infoj(j log.JSON) {
+	logger.Logger.Info(encode(j))
+}
+
+func (logger *EchoLogger) Warn(i ...interface{}) {
+	logger.Logger.Warn(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Warnj(j log.JSON) {
+	logger.Logger.Warn(encode(j))
+}
+
+func (logger *EchoLogger) Error(i ...interface{}) {
+	logger.Logger.Error(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Errorj(j log.JSON) {
+	logger.Logger.Error(encode(j))
+}
+
+func (logger *EchoLogger) Fatal(i ...interface{}) {
+	logger.Logger.Fatal(fmt.Sprint(i...))
+}
+
+func (logger *EchoLogger) Fatalj(j log.JSON) {
+	logger.Logger.Fatal(encode(j))
+}
+
+func (logger *EchoLogger) Panic(i ...interface{}) {
+	message := fmt.Sprint(i...)
+	logger.Logger.Error(message)
+	panic(message)
+}
+
+func (logger *EchoLogger) Panicj(j log.JSON) {
+	message := encode(j)
+	logger.Logger.Error(message)
+	panic(message)
+}
+
+func (logger *EchoLogger) Panicf(format string, args ...interface{}) {
+	logger.Panic(fmt.Sprintf(format, args...))
+}
+
+func encode(j log.JSON) string {
+	data, _ := json.Marshal(j)
+	return string(data)
+}
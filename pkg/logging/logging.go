@@ -0,0 +1,186 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+func (level Level) String() string {
+	return levelNames[level]
+}
+
+// ParseLevel reads level names as they are logged, "debug", "info", "warn" or "error"
+func ParseLevel(value string) (Level, error) {
+	for level, name := range levelNames {
+		if level != FatalLevel && strings.EqualFold(value, name) {
+			return level, nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("log level \"%s\" is not one of debug, info, warn, error", value)
+}
+
+// sink is shared by a logger and the loggers made from it by With,
+// so the level and the output set on any of them apply to all
+type sink struct {
+	mutex  sync.Mutex
+	output io.Writer
+	level  Level
+}
+
+// Logger writes every entry as a json line with "time", "level", "message" and the fields of the logger
+type Logger struct {
+	sink   *sink
+	fields map[string]interface{}
+	// exit is replaced in tests
+	exit func(int)
+}
+
+func New(output io.Writer, level Level) *Logger {
+	return &Logger{sink: &sink{output: output, level: level}, exit: os.Exit}
+}
+
+var defaultLogger = New(os.Stderr, InfoLevel)
+
+// Default is the logger of the app, it's configured by assembly
+// and used where there is no request to take a logger from, see From
+func Default() *Logger {
+	return defaultLogger
+}
+
+func (logger *Logger) SetOutput(output io.Writer) {
+	logger.sink.mutex.Lock()
+	defer logger.sink.mutex.Unlock()
+
+	logger.sink.output = output
+}
+
+func (logger *Logger) Output() io.Writer {
+	logger.sink.mutex.Lock()
+	defer logger.sink.mutex.Unlock()
+
+	return logger.sink.output
+}
+
+func (logger *Logger) SetLevel(level Level) {
+	logger.sink.mutex.Lock()
+	defer logger.sink.mutex.Unlock()
+
+	logger.sink.level = level
+}
+
+func (logger *Logger) Level() Level {
+	logger.sink.mutex.Lock()
+	defer logger.sink.mutex.Unlock()
+
+	return logger.sink.level
+}
+
+// With is a logger adding the field to every entry
+func (logger *Logger) With(key string, value interface{}) *Logger {
+	fields := make(map[string]interface{}, len(logger.fields)+1)
+	for k, v := range logger.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+
+	return &Logger{sink: logger.sink, fields: fields, exit: logger.exit}
+}
+
+// WithError adds the message of the error as "error" field
+func (logger *Logger) WithError(err error) *Logger {
+	return logger.With("error", err.Error())
+}
+
+func (logger *Logger) Debug(message string) {
+	logger.log(DebugLevel, message)
+}
+
+func (logger *Logger) Info(message string) {
+	logger.log(InfoLevel, message)
+}
+
+func (logger *Logger) Warn(message string) {
+	logger.log(WarnLevel, message)
+}
+
+func (logger *Logger) Error(message string) {
+	logger.log(ErrorLevel, message)
+}
+
+// Fatal logs the entry whatever the level is and exits
+func (logger *Logger) Fatal(message string) {
+	logger.log(FatalLevel, message)
+	logger.exit(1)
+}
+
+func (logger *Logger) Debugf(format string, args ...interface{}) {
+	logger.log(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (logger *Logger) Infof(format string, args ...interface{}) {
+	logger.log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (logger *Logger) Warnf(format string, args ...interface{}) {
+	logger.log(WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (logger *Logger) Errorf(format string, args ...interface{}) {
+	logger.log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (logger *Logger) Fatalf(format string, args ...interface{}) {
+	logger.Fatal(fmt.Sprintf(format, args...))
+}
+
+// log doesn't let fields replace time, level and message
+func (logger *Logger) log(level Level, message string) {
+	logger.sink.mutex.Lock()
+	defer logger.sink.mutex.Unlock()
+
+	if level < logger.sink.level {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(logger.fields)+3)
+	for key, value := range logger.fields {
+		entry[key] = value
+	}
+	entry["time"] = time.Now().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["message"] = message
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		data, _ = json.Marshal(map[string]interface{}{
+			"time":    entry["time"],
+			"level":   ErrorLevel.String(),
+			"message": "Error encoding log entry: " + err.Error(),
+		})
+	}
+	logger.sink.output.Write(append(data, '\n'))
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+
+	output *bytes.Buffer
+	logger *Logger
+	exited int
+}
+
+func (suite *LoggerTestSuite) SetupTest() {
+	suite.output = &bytes.Buffer{}
+	suite.logger = New(suite.output, InfoLevel)
+	suite.exited = -1
+	suite.logger.exit = func(code int) { suite.exited = code }
+}
+
+func (suite *LoggerTestSuite) entries() []map[string]interface{} {
+	entries := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(suite.output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func (suite *LoggerTestSuite) TestFields() {
+	requestLogger := suite.logger.With("request_id", "abc")
+	requestLogger.WithError(errors.New("mocked error")).With("message", "lost").Errorf("Error %s", "happened")
+	suite.logger.Info("Started")
+
+	entries := suite.entries()
+	suite.Assertions.Len(entries, 2)
+	suite.Assertions.Equal(entries[0]["level"], "error")
+	suite.Assertions.Equal(entries[0]["message"], "Error happened")
+	suite.Assertions.Equal(entries[0]["request_id"], "abc")
+	suite.Assertions.Equal(entries[0]["error"], "mocked error")
+	suite.Assertions.NotEmpty(entries[0]["time"])
+	suite.Assertions.NotContains(entries[1], "request_id")
+}
+
+func (suite *LoggerTestSuite) TestLevel() {
+	child := suite.logger.With("request_id", "abc")
+	suite.logger.SetLevel(WarnLevel)
+
+	child.Debug("debug")
+	child.Info("info")
+	child.Warn("warn")
+	child.Fatal("fatal")
+
+	entries := suite.entries()
+	suite.Assertions.Len(entries, 2)
+	suite.Assertions.Equal(entries[0]["level"], "warn")
+	suite.Assertions.Equal(entries[1]["level"], "fatal")
+	suite.Assertions.Equal(suite.exited, 1)
+}
+
+func (suite *LoggerTestSuite) TestParseLevel() {
+	level, err := ParseLevel("DEBUG")
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(level, DebugLevel)
+
+	for _, value := range []string{"", "fatal", "verbose"} {
+		_, err = ParseLevel(value)
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
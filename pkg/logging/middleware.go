@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+const loggerContextKey = "logging.logger"
+
+// maxRequestIDLength keeps ids of clients from bloating logs
+const maxRequestIDLength = 128
+
+type Config struct {
+	Skipper middleware.Skipper
+	Logger  *Logger
+}
+
+// Middleware takes the id of the request from X-Request-ID header or generates one,
+// sets it on the request and the response, stores the logger of the request on the context,
+// see From, puts both on the context of the request for the code below handlers, see FromContext,
+// and logs every request once it's served with the status of the response, so errors have to be rendered inside it
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.Logger == nil {
+		config.Logger = Default()
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			request := context.Request()
+			id := request.Header.Get(echo.HeaderXRequestID)
+			if !isRequestID(id) {
+				id = newRequestID()
+			}
+			request.Header.Set(echo.HeaderXRequestID, id)
+			context.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			logger := config.Logger.With("request_id", id)
+			context.Set(loggerContextKey, logger)
+			request = request.WithContext(WithRequestID(WithContext(request.Context(), logger), id))
+			context.SetRequest(request)
+
+			if config.Skipper(context) {
+				return next(context)
+			}
+
+			start := time.Now()
+			err := next(context)
+
+			response := context.Response()
+			logger = logger.
+				With("method", request.Method).
+				With("uri", request.RequestURI).
+				With("route", context.Path()).
+				With("status", response.Status).
+				With("bytes_out", response.Size).
+				With("latency_ms", float64(time.Since(start))/float64(time.Millisecond)).
+				With("remote_ip", context.RealIP())
+			if response.Status >= 500 {
+				logger.Error("Request served")
+			} else {
+				logger.Info("Request served")
+			}
+
+			return err
+		}
+	}
+}
+
+// From returns the logger of the request stored by Middleware, the default logger without it
+func From(context echo.Context) *Logger {
+	if logger, ok := context.Get(loggerContextKey).(*Logger); ok {
+		return logger
+	}
+
+	return Default()
+}
+
+// isRequestID accepts ids of clients that are safe to log and to pass on
+func isRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, char := range id {
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-' || char == '_' || char == '.' || char == ':') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
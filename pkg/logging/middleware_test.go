@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	output   *bytes.Buffer
+	recorder *httptest.ResponseRecorder
+	logger   *Logger
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.output = &bytes.Buffer{}
+	suite.recorder = httptest.NewRecorder()
+	suite.logger = nil
+}
+
+func (suite *MiddlewareTestSuite) serve(requestID string, handler echo.HandlerFunc) map[string]interface{} {
+	request := httptest.NewRequest(echo.GET, "/restaurants?page=2", nil)
+	if requestID != "" {
+		request.Header.Set(echo.HeaderXRequestID, requestID)
+	}
+	context := echo.New().NewContext(request, suite.recorder)
+	context.SetPath("/restaurants")
+
+	middleware := Middleware(Config{Logger: New(suite.output, InfoLevel)})
+	err := middleware(func(context echo.Context) error {
+		suite.logger = From(context)
+		suite.Assertions.Equal(FromContext(context.Request().Context()), suite.logger)
+		suite.Assertions.Equal(RequestIDFromContext(context.Request().Context()), context.Request().Header.Get(echo.HeaderXRequestID))
+		suite.Assertions.Equal(context.Request().Header.Get(echo.HeaderXRequestID), suite.recorder.Header().Get(echo.HeaderXRequestID))
+		return handler(context)
+	})(context)
+	suite.Assertions.Nil(err)
+
+	lines := strings.Split(strings.TrimSpace(suite.output.String()), "\n")
+	entry := map[string]interface{}{}
+	json.Unmarshal([]byte(lines[len(lines)-1]), &entry)
+
+	return entry
+}
+
+func (suite *MiddlewareTestSuite) TestRequestID() {
+	entry := suite.serve("client-id.1", func(context echo.Context) error {
+		From(context).Warn("Inside")
+		return context.NoContent(http.StatusOK)
+	})
+
+	suite.Assertions.Equal(suite.recorder.Header().Get(echo.HeaderXRequestID), "client-id.1")
+	suite.Assertions.Contains(suite.output.String(), `"message":"Inside","request_id":"client-id.1"`)
+	suite.Assertions.Equal(entry["request_id"], "client-id.1")
+	suite.Assertions.Equal(entry["message"], "Request served")
+	suite.Assertions.Equal(entry["route"], "/restaurants")
+	suite.Assertions.Equal(entry["uri"], "/restaurants?page=2")
+	suite.Assertions.Equal(entry["status"], float64(http.StatusOK))
+}
+
+func (suite *MiddlewareTestSuite) TestGeneratedRequestID() {
+	for _, requestID := range []string{"", "bad id\n{}", strings.Repeat("a", maxRequestIDLength+1)} {
+		suite.SetupTest()
+
+		entry := suite.serve(requestID, func(context echo.Context) error {
+			return context.NoContent(http.StatusOK)
+		})
+
+		id := suite.recorder.Header().Get(echo.HeaderXRequestID)
+		suite.Assertions.Len(id, 32, requestID)
+		suite.Assertions.Equal(entry["request_id"], id)
+	}
+}
+
+func (suite *MiddlewareTestSuite) TestError() {
+	entry := suite.serve("", func(context echo.Context) error {
+		// errors are rendered inside the middleware, by httperrors.Middleware in the app
+		context.Error(errors.New("mocked error"))
+		return nil
+	})
+
+	suite.Assertions.Equal(suite.recorder.Code, http.StatusInternalServerError)
+	suite.Assertions.Equal(entry["level"], "error")
+	suite.Assertions.Equal(entry["status"], float64(http.StatusInternalServerError))
+}
+
+func (suite *MiddlewareTestSuite) TestFromWithoutMiddleware() {
+	context := echo.New().NewContext(httptest.NewRequest(echo.GET, "/", nil), suite.recorder)
+
+	suite.Assertions.Equal(From(context), Default())
+}
+
+func (suite *MiddlewareTestSuite) TestFromContextWithoutMiddleware() {
+	suite.Assertions.Equal(FromContext(gocontext.Background()), Default())
+	suite.Assertions.Equal(RequestIDFromContext(gocontext.Background()), "")
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,73 @@
+// Package mergepatch applies JSON Merge Patch documents (RFC 7396):
+// fields of the patch replace fields of the document, null removes them
+// and nested objects are merged the same way
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// MIMEMergePatchJSON is the media type of merge patch request bodies
+const MIMEMergePatchJSON = "application/merge-patch+json"
+
+var errTrailingData = errors.New("mergepatch: data after the json value")
+
+// Apply returns the document with the patch applied,
+// a patch that is not an object replaces the whole document
+func Apply(document, patch []byte) ([]byte, error) {
+	target, err := decode(document)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(merge(target, changes))
+}
+
+// decode keeps numbers as they are written, so big integers are not rounded,
+// data has to be a single json value, anything but whitespace after it is an error
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var trailing interface{}
+	if err := decoder.Decode(&trailing); err != io.EOF {
+		return nil, errTrailingData
+	}
+
+	return value, nil
+}
+
+func merge(target, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = map[string]interface{}{}
+	}
+
+	for key, value := range changes {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = merge(result[key], value)
+	}
+
+	return result
+}
@@ -0,0 +1,61 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MergePatchTestSuite struct {
+	suite.Suite
+}
+
+// TestApply covers examples of RFC 7396 appendix A
+func (suite *MergePatchTestSuite) TestApply() {
+	for _, example := range []struct {
+		document, patch, expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"n":1}`, `{"n":12345678901234567890}`, `{"n":12345678901234567890}`},
+	} {
+		result, err := Apply([]byte(example.document), []byte(example.patch))
+
+		suite.Assertions.Nil(err)
+		suite.Assertions.JSONEq(string(result), example.expected, example.patch)
+	}
+}
+
+func (suite *MergePatchTestSuite) TestApplyInvalid() {
+	_, err := Apply([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	suite.Assertions.Error(err)
+
+	_, err = Apply([]byte(`not json`), []byte(`{"a":"b"}`))
+	suite.Assertions.Error(err)
+
+	for _, patch := range []string{`{"a":"b"} garbage`, `{}{"a":"b"}`, `{"a":"b"}}`} {
+		_, err = Apply([]byte(`{"a":"c"}`), []byte(patch))
+		suite.Assertions.Error(err, patch)
+	}
+
+	result, err := Apply([]byte(`{"a":"c"}`), []byte("{\"a\":\"b\"}\n "))
+	suite.Assertions.Nil(err)
+	suite.Assertions.JSONEq(string(result), `{"a":"b"}`)
+}
+
+func TestMergePatchTestSuite(t *testing.T) {
+	suite.Run(t, new(MergePatchTestSuite))
+}
@@ -0,0 +1,267 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets of latency histograms in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metric is written in Prometheus text exposition format
+type metric interface {
+	name() string
+	write(*bufio.Writer)
+}
+
+// Registry keeps metrics in the order they are made
+type Registry struct {
+	mutex   sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default is the registry exposed by the app
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// register panics on a repeated name, a metric is made once by the code owning it
+func (registry *Registry) register(m metric) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	if registry.names[m.name()] {
+		panic(fmt.Sprintf("metric %s is already registered", m.name()))
+	}
+	registry.names[m.name()] = true
+	registry.metrics = append(registry.metrics, m)
+}
+
+// Write renders all metrics in Prometheus text exposition format
+func (registry *Registry) Write(output io.Writer) error {
+	registry.mutex.Lock()
+	metrics := append([]metric(nil), registry.metrics...)
+	registry.mutex.Unlock()
+
+	writer := bufio.NewWriter(output)
+	for _, m := range metrics {
+		m.write(writer)
+	}
+
+	return writer.Flush()
+}
+
+// vec is a family of series told apart by values of the labels
+type vec struct {
+	metricName string
+	help       string
+	labels     []string
+
+	mutex  sync.Mutex
+	series map[string][]string
+}
+
+func (v *vec) name() string {
+	return v.metricName
+}
+
+// key of the series, labels values are checked to be as many as the labels
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", v.metricName, len(v.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	if _, ok := v.series[key]; !ok {
+		v.series[key] = append([]string(nil), values...)
+	}
+
+	return key
+}
+
+// sortedKeys keep the output stable between scrapes
+func (v *vec) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (v *vec) writeHeader(writer *bufio.Writer, kind string) {
+	fmt.Fprintf(writer, "# HELP %s %s\n", v.metricName, escape(v.help, false))
+	fmt.Fprintf(writer, "# TYPE %s %s\n", v.metricName, kind)
+}
+
+// labelPairs renders {label="value",...}, extra pairs go last
+func (v *vec) labelPairs(values []string, extra ...string) string {
+	pairs := make([]string, 0, len(values)+1)
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, v.labels[i], escape(value, true)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterVec counts events by labels
+type CounterVec struct {
+	vec
+
+	values map[string]float64
+}
+
+func (registry *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		vec:    vec{metricName: name, help: help, labels: labels, series: map[string][]string{}},
+		values: map[string]float64{},
+	}
+	registry.register(counter)
+
+	return counter
+}
+
+func (counter *CounterVec) Inc(values ...string) {
+	counter.Add(1, values...)
+}
+
+func (counter *CounterVec) Add(delta float64, values ...string) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	counter.values[counter.key(values)] += delta
+}
+
+// Value is the count of the series, 0 when it wasn't counted
+func (counter *CounterVec) Value(values ...string) float64 {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	return counter.values[strings.Join(values, "\xff")]
+}
+
+func (counter *CounterVec) write(writer *bufio.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	counter.writeHeader(writer, "counter")
+	for _, key := range counter.sortedKeys() {
+		fmt.Fprintf(writer, "%s%s %s\n", counter.metricName, counter.labelPairs(counter.series[key]), formatFloat(counter.values[key]))
+	}
+}
+
+// HistogramVec counts observations by labels into cumulative buckets
+type HistogramVec struct {
+	vec
+
+	buckets []float64
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec sorts the buckets, +Inf bucket is always added
+func (registry *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	histogramVec := &HistogramVec{
+		vec:     vec{metricName: name, help: help, labels: labels, series: map[string][]string{}},
+		buckets: buckets,
+		values:  map[string]*histogram{},
+	}
+	registry.register(histogramVec)
+
+	return histogramVec
+}
+
+func (histogramVec *HistogramVec) Observe(value float64, values ...string) {
+	histogramVec.mutex.Lock()
+	defer histogramVec.mutex.Unlock()
+
+	key := histogramVec.key(values)
+	series, ok := histogramVec.values[key]
+	if !ok {
+		series = &histogram{counts: make([]uint64, len(histogramVec.buckets))}
+		histogramVec.values[key] = series
+	}
+
+	for i, bound := range histogramVec.buckets {
+		if value <= bound {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+// Count is the number of observations of the series
+func (histogramVec *HistogramVec) Count(values ...string) uint64 {
+	histogramVec.mutex.Lock()
+	defer histogramVec.mutex.Unlock()
+
+	if series, ok := histogramVec.values[strings.Join(values, "\xff")]; ok {
+		return series.count
+	}
+
+	return 0
+}
+
+func (histogramVec *HistogramVec) write(writer *bufio.Writer) {
+	histogramVec.mutex.Lock()
+	defer histogramVec.mutex.Unlock()
+
+	histogramVec.writeHeader(writer, "histogram")
+	for _, key := range histogramVec.sortedKeys() {
+		values, series := histogramVec.series[key], histogramVec.values[key]
+		for i, bound := range histogramVec.buckets {
+			fmt.Fprintf(writer, "%s_bucket%s %d\n", histogramVec.metricName, histogramVec.labelPairs(values, "le", formatFloat(bound)), series.counts[i])
+		}
+		fmt.Fprintf(writer, "%s_bucket%s %d\n", histogramVec.metricName, histogramVec.labelPairs(values, "le", "+Inf"), series.count)
+		fmt.Fprintf(writer, "%s_sum%s %s\n", histogramVec.metricName, histogramVec.labelPairs(values), formatFloat(series.sum))
+		fmt.Fprintf(writer, "%s_count%s %d\n", histogramVec.metricName, histogramVec.labelPairs(values), series.count)
+	}
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escape backslashes and line breaks of help texts, and quotes of label values as well
+func escape(value string, quotes bool) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	if quotes {
+		value = strings.Replace(value, `"`, `\"`, -1)
+	}
+
+	return value
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RegistryTestSuite struct {
+	suite.Suite
+
+	registry *Registry
+}
+
+func (suite *RegistryTestSuite) SetupTest() {
+	suite.registry = NewRegistry()
+}
+
+func (suite *RegistryTestSuite) write() string {
+	output := &bytes.Buffer{}
+	if err := suite.registry.Write(output); err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	return output.String()
+}
+
+func (suite *RegistryTestSuite) TestCounter() {
+	counter := suite.registry.NewCounterVec("requests_total", "Requests\nserved.", "route")
+	counter.Inc("/b")
+	counter.Add(2, `/a"\`)
+	counter.Inc("/b")
+
+	suite.Assertions.Equal(counter.Value("/b"), float64(2))
+	suite.Assertions.Equal(suite.write(), `# HELP requests_total Requests\nserved.
+# TYPE requests_total counter
+requests_total{route="/a\"\\"} 2
+requests_total{route="/b"} 2
+`)
+}
+
+func (suite *RegistryTestSuite) TestHistogram() {
+	histogram := suite.registry.NewHistogramVec("duration_seconds", "Latency.", []float64{1, 0.5})
+	histogram.Observe(0.2)
+	histogram.Observe(0.7)
+	histogram.Observe(3)
+
+	suite.Assertions.Equal(histogram.Count(), uint64(3))
+	suite.Assertions.Equal(suite.write(), `# HELP duration_seconds Latency.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.5"} 1
+duration_seconds_bucket{le="1"} 2
+duration_seconds_bucket{le="+Inf"} 3
+duration_seconds_sum 3.9
+duration_seconds_count 3
+`)
+}
+
+func (suite *RegistryTestSuite) TestRegisterFail() {
+	suite.registry.NewCounterVec("requests_total", "Requests.")
+
+	suite.Assertions.Panics(func() { suite.registry.NewHistogramVec("requests_total", "Requests.", DefaultBuckets) })
+	suite.Assertions.Panics(func() { suite.registry.NewCounterVec("other_total", "Other.", "route").Inc() })
+}
+
+func TestRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// contentType of Prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// unmatchedRoute labels requests no route was found for, so scanning clients don't make a series per url
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics count requests by methods, routes as they are registered and statuses
+type HTTPMetrics struct {
+	Requests  *CounterVec
+	Durations *HistogramVec
+
+	routesOnce sync.Once
+	routes     map[string]bool
+}
+
+func NewHTTPMetrics(registry *Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		Requests:  registry.NewCounterVec("http_requests_total", "Served HTTP requests.", "method", "route", "status"),
+		Durations: registry.NewHistogramVec("http_request_duration_seconds", "Latency of HTTP requests.", DefaultBuckets, "method", "route"),
+	}
+}
+
+// Middleware measures every request by the status of the response, errors have to be rendered inside it
+func (httpMetrics *HTTPMetrics) Middleware(skipper middleware.Skipper) echo.MiddlewareFunc {
+	if skipper == nil {
+		skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if skipper(context) {
+				return next(context)
+			}
+
+			start := time.Now()
+			err := next(context)
+
+			method := context.Request().Method
+			route := context.Path()
+			if !httpMetrics.isRoute(context.Echo(), route) {
+				route = unmatchedRoute
+			}
+
+			httpMetrics.Durations.Observe(time.Since(start).Seconds(), method, route)
+			httpMetrics.Requests.Inc(method, route, strconv.Itoa(context.Response().Status))
+
+			return err
+		}
+	}
+}
+
+// isRoute tells paths of registered routes from urls echo reports as paths of unmatched requests,
+// routes are read once as they are all registered before the app starts serving
+func (httpMetrics *HTTPMetrics) isRoute(e *echo.Echo, path string) bool {
+	httpMetrics.routesOnce.Do(func() {
+		httpMetrics.routes = map[string]bool{}
+		for _, route := range e.Routes() {
+			httpMetrics.routes[route.Path] = true
+		}
+	})
+
+	return httpMetrics.routes[path]
+}
+
+// Handler exposes metrics of the registry
+func Handler(registry *Registry) echo.HandlerFunc {
+	return func(context echo.Context) error {
+		buffer := &bytes.Buffer{}
+		if err := registry.Write(buffer); err != nil {
+			return err
+		}
+
+		return context.Blob(http.StatusOK, contentType, buffer.Bytes())
+	}
+}
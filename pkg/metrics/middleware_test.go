@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	registry    *Registry
+	httpMetrics *HTTPMetrics
+	echo        *echo.Echo
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.registry = NewRegistry()
+	suite.httpMetrics = NewHTTPMetrics(suite.registry)
+	suite.echo = echo.New()
+	suite.echo.Use(suite.httpMetrics.Middleware(nil), httperrors.Middleware())
+	suite.echo.GET("/restaurants/:restaurant_id", func(context echo.Context) error {
+		if context.Param("restaurant_id") == "fail" {
+			return errors.New("mocked error")
+		}
+		return context.NoContent(http.StatusOK)
+	})
+	suite.echo.GET("/metrics", Handler(suite.registry))
+}
+
+func (suite *MiddlewareTestSuite) serve(target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	suite.echo.ServeHTTP(recorder, httptest.NewRequest(echo.GET, target, nil))
+
+	return recorder
+}
+
+func (suite *MiddlewareTestSuite) TestRoutes() {
+	suite.serve("/restaurants/1")
+	suite.serve("/restaurants/2")
+	suite.serve("/restaurants/fail")
+	suite.serve("/unknown/url")
+
+	requests := suite.httpMetrics.Requests
+	suite.Assertions.Equal(requests.Value("GET", "/restaurants/:restaurant_id", "200"), float64(2))
+	suite.Assertions.Equal(requests.Value("GET", "/restaurants/:restaurant_id", "500"), float64(1))
+	suite.Assertions.Equal(requests.Value("GET", unmatchedRoute, "404"), float64(1))
+	suite.Assertions.Equal(suite.httpMetrics.Durations.Count("GET", "/restaurants/:restaurant_id"), uint64(3))
+}
+
+func (suite *MiddlewareTestSuite) TestHandler() {
+	suite.serve("/restaurants/1")
+
+	recorder := suite.serve("/metrics")
+
+	suite.Assertions.Equal(recorder.Code, http.StatusOK)
+	suite.Assertions.Equal(recorder.Header().Get(echo.HeaderContentType), contentType)
+	suite.Assertions.True(strings.Contains(recorder.Body.String(), `http_requests_total{method="GET",route="/restaurants/:restaurant_id",status="200"} 1`))
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
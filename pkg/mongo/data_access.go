@@ -1,6 +1,14 @@
 package mongo
 
-import "gopkg.in/mgo.v2"
+import (
+	"context"
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+// errCodeExceededTimeLimit is the code of queries stopped by mongo when their max time passes
+const errCodeExceededTimeLimit = 50
 
 var (
 	_ DataAccessor = new(DataAccess)
@@ -8,69 +16,186 @@ var (
 )
 
 type DataAccessor interface {
-	Find(interface{}) Querier
-	Insert(interface{}) error
-	Update(interface{}, interface{}) error
-	Remove(interface{}) error
+	Find(context.Context, interface{}) Querier
+	Insert(context.Context, interface{}) error
+	Update(context.Context, interface{}, interface{}) error
+	Remove(context.Context, interface{}) error
+	RemoveAll(context.Context, interface{}) (int, error)
+	EnsureIndex(mgo.Index) error
 }
 
 type Querier interface {
 	Select(interface{}) Querier
 	All(interface{}) error
 	One(interface{}) error
-	Sort(string) Querier
+	Count() (int, error)
+	Sort(...string) Querier
 	Skip(int) Querier
 	Limit(int) Querier
 }
 
+// DataAccess runs every operation of a context with a deadline or a cancellation on a copy of the session,
+// mgo gives up the operation when the socket timeout of the copy, the time left until the deadline, passes,
+// and the operation is left to finish on its own when the context is done earlier.
+// Queries are also stopped by mongo itself when their max time, the same time left, passes.
 type DataAccess struct {
 	Collection *mgo.Collection
 }
 
-func (da *DataAccess) Find(query interface{}) Querier {
-	return &Query{da.Collection.Find(query)}
+// do runs the operation with the collection of the session it has to use
+func (da *DataAccess) do(ctx context.Context, operation func(*mgo.Collection) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline && ctx.Done() == nil {
+		return operation(da.Collection)
+	}
+
+	session := da.Collection.Database.Session.Copy()
+	if hasDeadline {
+		left := time.Until(deadline)
+		if left < time.Millisecond {
+			session.Close()
+			return context.DeadlineExceeded
+		}
+		session.SetSocketTimeout(left)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		// the session is closed once the operation stops using it
+		defer session.Close()
+		done <- operation(da.Collection.With(session))
+	}()
+
+	select {
+	case err := <-done:
+		// the socket timeout fails the operation by a network error
+		if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (da *DataAccess) Find(ctx context.Context, query interface{}) Querier {
+	return &Query{access: da, ctx: ctx, query: query}
+}
+
+func (da *DataAccess) Insert(ctx context.Context, object interface{}) error {
+	return da.do(ctx, func(collection *mgo.Collection) error {
+		return collection.Insert(object)
+	})
+}
+
+func (da *DataAccess) Update(ctx context.Context, query interface{}, object interface{}) error {
+	return da.do(ctx, func(collection *mgo.Collection) error {
+		return collection.Update(query, object)
+	})
 }
 
-func (da *DataAccess) Insert(object interface{}) error {
-	return da.Collection.Insert(object)
+func (da *DataAccess) Remove(ctx context.Context, query interface{}) error {
+	return da.do(ctx, func(collection *mgo.Collection) error {
+		return collection.Remove(query)
+	})
 }
 
-func (da *DataAccess) Update(query interface{}, object interface{}) error {
-	return da.Collection.Update(query, object)
+func (da *DataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	var removed int
+	err := da.do(ctx, func(collection *mgo.Collection) error {
+		info, err := collection.RemoveAll(query)
+		if err != nil {
+			return err
+		}
+
+		removed = info.Removed
+		return nil
+	})
+
+	return removed, err
 }
 
-func (da *DataAccess) Remove(query interface{}) error {
-	return da.Collection.Remove(query)
+func (da *DataAccess) EnsureIndex(index mgo.Index) error {
+	return da.Collection.EnsureIndex(index)
 }
 
+// Query keeps how the query is built, it's built on the collection of the session it's run with
 type Query struct {
-	query *mgo.Query
+	access *DataAccess
+	ctx    context.Context
+	query  interface{}
+	steps  []func(*mgo.Query) *mgo.Query
 }
 
-func(q *Query) Select(fields interface{}) Querier {
-	q.query = q.query.Select(fields)
+// run sends the query with the time left until the deadline of the context as its max time,
+// mgo doesn't send the max time of Count, it's limited by the socket timeout only
+func(q *Query) run(run func(*mgo.Query) error) error {
+	return q.access.do(q.ctx, func(collection *mgo.Collection) error {
+		query := collection.Find(q.query)
+		for _, step := range q.steps {
+			query = step(query)
+		}
+		if deadline, ok := q.ctx.Deadline(); ok {
+			left := time.Until(deadline)
+			// max time is sent in milliseconds, 0 would mean no limit at all
+			if left < time.Millisecond {
+				return context.DeadlineExceeded
+			}
+			query = query.SetMaxTime(left)
+		}
+
+		err := run(query)
+		if queryError, ok := err.(*mgo.QueryError); ok && queryError.Code == errCodeExceededTimeLimit {
+			return context.DeadlineExceeded
+		}
+
+		return err
+	})
+}
+
+func(q *Query) then(step func(*mgo.Query) *mgo.Query) Querier {
+	q.steps = append(q.steps, step)
 	return q
 }
 
+func(q *Query) Select(fields interface{}) Querier {
+	return q.then(func(query *mgo.Query) *mgo.Query { return query.Select(fields) })
+}
+
 func(q *Query) All(result interface{}) error {
-	return q.query.All(result)
+	return q.run(func(query *mgo.Query) error {
+		return query.All(result)
+	})
 }
 
 func(q *Query) One(result interface{}) error {
-	return q.query.One(result)
+	return q.run(func(query *mgo.Query) error {
+		return query.One(result)
+	})
 }
 
-func(q *Query) Sort(ordering string) Querier {
-	q.query = q.query.Sort(ordering)
-	return q
+func(q *Query) Count() (int, error) {
+	var count int
+	err := q.run(func(query *mgo.Query) (err error) {
+		count, err = query.Count()
+		return err
+	})
+
+	return count, err
+}
+
+func(q *Query) Sort(fields ...string) Querier {
+	return q.then(func(query *mgo.Query) *mgo.Query { return query.Sort(fields...) })
 }
 
 func(q *Query) Skip(n int) Querier {
-	q.query = q.query.Skip(n)
-	return q
+	return q.then(func(query *mgo.Query) *mgo.Query { return query.Skip(n) })
 }
 
 func(q *Query) Limit(n int) Querier {
-	q.query = q.query.Limit(n)
-	return q
+	return q.then(func(query *mgo.Query) *mgo.Query { return query.Limit(n) })
 }
@@ -0,0 +1,132 @@
+package mongo
+
+import (
+	"math"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// EarthRadius in meters is the one mongo uses for spherical geometry,
+// distances are divided by it to get radians for $centerSphere
+const EarthRadius = 6378100.0
+
+// SphereDistance is the distance in meters between two [longitude, latitude] points
+func SphereDistance(a, b []float64) float64 {
+	lng1, lat1 := toRadians(a[0]), toRadians(a[1])
+	lng2, lat2 := toRadians(b[0]), toRadians(b[1])
+
+	sinLat := math.Sin((lat2 - lat1) / 2)
+	sinLng := math.Sin((lng2 - lng1) / 2)
+	h := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLng*sinLng
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// pointCoordinates reads [longitude, latitude] of a GeoJSON point
+// or of a legacy coordinate pair
+func pointCoordinates(value interface{}) ([]float64, bool) {
+	if point, ok := value.(bson.M); ok {
+		if point["type"] != "Point" {
+			return nil, false
+		}
+		value = point["coordinates"]
+	}
+
+	items, ok := value.([]interface{})
+	if !ok || len(items) != 2 {
+		return nil, false
+	}
+
+	coordinates := make([]float64, 2)
+	for i, item := range items {
+		if coordinates[i], ok = toFloat(item); !ok {
+			return nil, false
+		}
+	}
+
+	return coordinates, true
+}
+
+// matchCenterSphere supports {"$centerSphere": [[lng, lat], radians]} shape of $geoWithin
+func matchCenterSphere(values []interface{}, argument interface{}) bool {
+	shape, _ := argument.(bson.M)
+	sphere, _ := shape["$centerSphere"].([]interface{})
+	if len(sphere) != 2 {
+		return false
+	}
+
+	center, ok := pointCoordinates(sphere[0])
+	radius, isNumber := toFloat(sphere[1])
+	if !ok || !isNumber {
+		return false
+	}
+
+	return anyValue(values, func(value interface{}) bool {
+		coordinates, ok := pointCoordinates(value)
+		return ok && SphereDistance(center, coordinates) <= radius*EarthRadius
+	})
+}
+
+// nearSphere is {"$geometry": point, "$maxDistance": meters} argument of $nearSphere
+func nearSphere(argument interface{}) ([]float64, float64, bool) {
+	near, _ := argument.(bson.M)
+	center, ok := pointCoordinates(near["$geometry"])
+	if !ok {
+		return nil, 0, false
+	}
+
+	maxDistance, ok := toFloat(near["$maxDistance"])
+	if !ok {
+		maxDistance = math.Inf(1)
+	}
+
+	return center, maxDistance, true
+}
+
+func matchNearSphere(values []interface{}, argument interface{}) bool {
+	center, maxDistance, ok := nearSphere(argument)
+	if !ok {
+		return false
+	}
+
+	return anyValue(values, func(value interface{}) bool {
+		coordinates, ok := pointCoordinates(value)
+		return ok && SphereDistance(center, coordinates) <= maxDistance
+	})
+}
+
+// nearOrdering finds $nearSphere condition of the filter the same way as textSearch,
+// documents are ordered by distance to its point then
+func nearOrdering(filter bson.M) (string, []float64, bool) {
+	for key, condition := range filter {
+		operators, ok := condition.(bson.M)
+		if !ok {
+			continue
+		}
+
+		if center, _, ok := nearSphere(operators["$nearSphere"]); ok {
+			return key, center, true
+		}
+	}
+
+	for _, sub := range toDocuments(filter["$and"]) {
+		if key, center, ok := nearOrdering(sub); ok {
+			return key, center, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func nearDistance(document bson.M, key string, center []float64) float64 {
+	coordinates, ok := pointCoordinates(lookupValue(document, key))
+	if !ok {
+		return math.Inf(1)
+	}
+
+	return SphereDistance(center, coordinates)
+}
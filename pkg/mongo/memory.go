@@ -0,0 +1,978 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	_ DataAccessor = new(MemoryDataAccess)
+	_ Querier      = new(MemoryQuery)
+)
+
+var errDuplicateKey = &mgo.LastError{Code: 11000, Err: "E11000 duplicate key error index: _id_"}
+
+// MemoryDataAccess keeps documents of a single collection in process memory.
+// Documents are stored in their bson representation, so anything that can be
+// stored with DataAccess can be stored here as well. Operations of a done
+// context fail with its error, as they do with DataAccess.
+type MemoryDataAccess struct {
+	mutex     sync.RWMutex
+	documents []bson.M
+	// textFields are weights of fields of the text index, $text requires it
+	textFields map[string]int
+}
+
+func (da *MemoryDataAccess) Find(ctx context.Context, query interface{}) Querier {
+	filter, err := toDocument(query)
+	return &MemoryQuery{storage: da, ctx: ctx, filter: filter, err: err, limit: -1}
+}
+
+func (da *MemoryDataAccess) Insert(ctx context.Context, object interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	document, err := toDocument(object)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := document["_id"]; !ok {
+		document["_id"] = bson.NewObjectId()
+	}
+
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	for _, stored := range da.documents {
+		if equalValues(stored["_id"], document["_id"]) {
+			return errDuplicateKey
+		}
+	}
+	da.documents = append(da.documents, document)
+
+	return nil
+}
+
+func (da *MemoryDataAccess) Update(ctx context.Context, query interface{}, object interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filter, err := toDocument(query)
+	if err != nil {
+		return err
+	}
+
+	update, err := toDocument(object)
+	if err != nil {
+		return err
+	}
+
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	for i, document := range da.documents {
+		if !matchDocument(document, filter) {
+			continue
+		}
+
+		updated, err := applyUpdate(document, filter, update)
+		if err != nil {
+			return err
+		}
+		da.documents[i] = updated
+
+		return nil
+	}
+
+	return mgo.ErrNotFound
+}
+
+func (da *MemoryDataAccess) Remove(ctx context.Context, query interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	filter, err := toDocument(query)
+	if err != nil {
+		return err
+	}
+
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	for i, document := range da.documents {
+		if matchDocument(document, filter) {
+			da.documents = append(da.documents[:i], da.documents[i+1:]...)
+			return nil
+		}
+	}
+
+	return mgo.ErrNotFound
+}
+
+// RemoveAll removes every matching document and tells how many were removed.
+func (da *MemoryDataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	filter, err := toDocument(query)
+	if err != nil {
+		return 0, err
+	}
+
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	kept := da.documents[:0]
+	for _, document := range da.documents {
+		if !matchDocument(document, filter) {
+			kept = append(kept, document)
+		}
+	}
+	removed := len(da.documents) - len(kept)
+	da.documents = kept
+
+	return removed, nil
+}
+
+// EnsureIndex keeps only the text index, since it changes what $text matches,
+// other indexes don't change query results.
+func (da *MemoryDataAccess) EnsureIndex(index mgo.Index) error {
+	textFields := map[string]int{}
+	for _, key := range index.Key {
+		if !strings.HasPrefix(key, "$text:") {
+			continue
+		}
+
+		field := strings.TrimPrefix(key, "$text:")
+		textFields[field] = 1
+		if weight, ok := index.Weights[field]; ok {
+			textFields[field] = weight
+		}
+	}
+
+	if len(textFields) == 0 {
+		return nil
+	}
+
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	if da.textFields != nil && !reflect.DeepEqual(da.textFields, textFields) {
+		return errors.New("only one text index per collection is allowed")
+	}
+	da.textFields = textFields
+
+	return nil
+}
+
+// DropCollection removes every stored document.
+func (da *MemoryDataAccess) DropCollection() error {
+	da.mutex.Lock()
+	defer da.mutex.Unlock()
+
+	da.documents = nil
+
+	return nil
+}
+
+func (da *MemoryDataAccess) find(filter bson.M) []bson.M {
+	da.mutex.RLock()
+	defer da.mutex.RUnlock()
+
+	var result []bson.M
+	for _, document := range da.documents {
+		if matchDocument(document, filter) {
+			result = append(result, document)
+		}
+	}
+
+	return result
+}
+
+func NewMemoryDataAccess() *MemoryDataAccess {
+	return &MemoryDataAccess{}
+}
+
+type MemoryQuery struct {
+	storage  *MemoryDataAccess
+	ctx      context.Context
+	filter   bson.M
+	fields   bson.M
+	ordering []string
+	skip     int
+	limit    int
+	err      error
+}
+
+func (q *MemoryQuery) Select(fields interface{}) Querier {
+	document, err := toDocument(fields)
+	if err != nil {
+		q.err = err
+	}
+	q.fields = document
+	return q
+}
+
+func (q *MemoryQuery) All(result interface{}) error {
+	documents, err := q.run()
+	if err != nil {
+		return err
+	}
+
+	resultValue := reflect.ValueOf(result)
+	if resultValue.Kind() != reflect.Ptr || resultValue.Elem().Kind() != reflect.Slice {
+		return errors.New("result argument must be a slice address")
+	}
+
+	sliceValue := resultValue.Elem()
+	sliceValue = sliceValue.Slice(0, sliceValue.Cap())
+	elementType := sliceValue.Type().Elem()
+
+	i := 0
+	for _, document := range documents {
+		element := reflect.New(elementType)
+		if err := fromDocument(document, element.Interface()); err != nil {
+			return err
+		}
+
+		if i < sliceValue.Len() {
+			sliceValue.Index(i).Set(element.Elem())
+		} else {
+			sliceValue = reflect.Append(sliceValue, element.Elem())
+		}
+		i++
+	}
+	resultValue.Elem().Set(sliceValue.Slice(0, i))
+
+	return nil
+}
+
+func (q *MemoryQuery) One(result interface{}) error {
+	documents, err := q.run()
+	if err != nil {
+		return err
+	}
+
+	if len(documents) == 0 {
+		return mgo.ErrNotFound
+	}
+
+	return fromDocument(documents[0], result)
+}
+
+// Count respects Skip and Limit the same way mgo does
+func (q *MemoryQuery) Count() (int, error) {
+	documents, err := q.run()
+	return len(documents), err
+}
+
+func (q *MemoryQuery) Sort(fields ...string) Querier {
+	q.ordering = fields
+	return q
+}
+
+func (q *MemoryQuery) Skip(n int) Querier {
+	q.skip = n
+	return q
+}
+
+func (q *MemoryQuery) Limit(n int) Querier {
+	q.limit = n
+	return q
+}
+
+func (q *MemoryQuery) run() ([]bson.M, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if err := q.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	documents := q.storage.find(q.filter)
+
+	if search, ok := textSearch(q.filter); ok {
+		var err error
+		if documents, err = q.storage.scoreText(documents, search); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(q.ordering) > 0 {
+		sort.SliceStable(documents, func(i, j int) bool {
+			return compareDocuments(documents[i], documents[j], q.ordering) < 0
+		})
+	} else if key, center, ok := nearOrdering(q.filter); ok {
+		sort.SliceStable(documents, func(i, j int) bool {
+			return nearDistance(documents[i], key, center) < nearDistance(documents[j], key, center)
+		})
+	}
+
+	if q.skip >= len(documents) {
+		return nil, nil
+	}
+	documents = documents[q.skip:]
+
+	if q.limit >= 0 && q.limit < len(documents) {
+		documents = documents[:q.limit]
+	}
+
+	projected := make([]bson.M, 0, len(documents))
+	for _, document := range documents {
+		projected = append(projected, projectDocument(document, q.fields))
+	}
+
+	return projected, nil
+}
+
+func compareDocuments(a, b bson.M, ordering []string) int {
+	for _, field := range ordering {
+		direction := 1
+		switch {
+		case strings.HasPrefix(field, "-"):
+			field, direction = field[1:], -1
+		case strings.HasPrefix(field, "$textScore:"):
+			// the most relevant go first, the same as in mongo
+			field, direction = textScoreKey, -1
+		}
+
+		if result := compareValues(lookupValue(a, field), lookupValue(b, field)); result != 0 {
+			return result * direction
+		}
+	}
+
+	return 0
+}
+
+// toDocument converts any bson marshalable value to a fresh bson.M copy,
+// so structs with omitempty tags behave as they do when sent to mongo.
+func toDocument(object interface{}) (bson.M, error) {
+	if object == nil {
+		return bson.M{}, nil
+	}
+
+	data, err := bson.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	document := bson.M{}
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+func fromDocument(document bson.M, result interface{}) error {
+	data, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(data, result)
+}
+
+func projectDocument(document bson.M, fields bson.M) bson.M {
+	projected := bson.M{}
+
+	include := false
+	for key, value := range fields {
+		if isTextScoreMeta(value) {
+			projected[key] = document[textScoreKey]
+			continue
+		}
+		if key != "_id" && isTruthy(value) {
+			include = true
+		}
+	}
+
+	for key, value := range document {
+		flag, ok := fields[key]
+		switch {
+		case key == textScoreKey || isTextScoreMeta(flag):
+			// the score is rendered only where it's asked for
+		case key == "_id" && ok:
+			if isTruthy(flag) {
+				projected[key] = value
+			}
+		case key == "_id":
+			projected[key] = value
+		case include && ok && isTruthy(flag):
+			projected[key] = value
+		case !include && !ok:
+			projected[key] = value
+		}
+	}
+
+	return projected
+}
+
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case nil:
+		return false
+	default:
+		number, ok := toFloat(v)
+		return !ok || number != 0
+	}
+}
+
+func applyUpdate(document bson.M, filter bson.M, update bson.M) (bson.M, error) {
+	if !hasOperators(update) {
+		replaced := bson.M{}
+		for key, value := range update {
+			replaced[key] = value
+		}
+		replaced["_id"] = document["_id"]
+
+		return replaced, nil
+	}
+
+	updated, err := toDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	position := positionalIndex(document, filter)
+	for operator, arguments := range update {
+		fields, ok := arguments.(bson.M)
+		if !ok {
+			return nil, fmt.Errorf("modifier %s requires a document", operator)
+		}
+
+		for path, value := range fields {
+			if strings.Contains(path, ".$") {
+				if position < 0 {
+					return nil, errors.New("the positional operator did not find the match needed from the query")
+				}
+				path = strings.Replace(path, ".$", "."+strconv.Itoa(position), 1)
+			}
+
+			switch operator {
+			case "$set":
+				err = setValue(updated, path, value)
+			case "$unset":
+				unsetValue(updated, path)
+			case "$inc":
+				err = incValue(updated, path, value)
+			case "$push":
+				current, _ := lookupValue(updated, path).([]interface{})
+				err = setValue(updated, path, append(current, value))
+			case "$addToSet":
+				current, _ := lookupValue(updated, path).([]interface{})
+				err = setValue(updated, path, addToSet(current, value))
+			case "$pull":
+				current, _ := lookupValue(updated, path).([]interface{})
+				err = setValue(updated, path, pullValues(current, value))
+			default:
+				err = fmt.Errorf("unsupported update operator %s", operator)
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+func hasOperators(document bson.M) bool {
+	for key := range document {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// positionalIndex finds the array element matched by the filter,
+// it is the element "$" refers to in update paths like "menu.$.name"
+func positionalIndex(document bson.M, filter bson.M) int {
+	for key, condition := range filter {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		items, ok := document[parts[0]].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for i, item := range items {
+			subDocument, ok := item.(bson.M)
+			if ok && matchDocument(subDocument, bson.M{parts[1]: condition}) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+func pullValues(items []interface{}, condition interface{}) []interface{} {
+	kept := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		subDocument, isDocument := item.(bson.M)
+		conditionDocument, isConditionDocument := condition.(bson.M)
+
+		var matched bool
+		switch {
+		case isConditionDocument && hasOperators(conditionDocument):
+			matched = matchField([]interface{}{item}, condition)
+		case isConditionDocument && isDocument:
+			matched = matchDocument(subDocument, conditionDocument)
+		default:
+			matched = equalValues(item, condition)
+		}
+
+		if !matched {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+// addToSet appends the value unless the array already has it
+func addToSet(items []interface{}, value interface{}) []interface{} {
+	for _, item := range items {
+		if equalValues(item, value) {
+			return items
+		}
+	}
+
+	return append(items, value)
+}
+
+func setValue(document bson.M, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+
+	var current interface{} = document
+	for i, key := range keys {
+		last := i == len(keys)-1
+
+		switch container := current.(type) {
+		case bson.M:
+			if last {
+				container[key] = value
+				return nil
+			}
+
+			next, ok := container[key]
+			if !ok || next == nil {
+				next = bson.M{}
+				container[key] = next
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(container) {
+				return fmt.Errorf("cannot use the part (%s) of (%s) to traverse the element", key, path)
+			}
+
+			if last {
+				container[index] = value
+				return nil
+			}
+			current = container[index]
+		default:
+			return fmt.Errorf("cannot use the part (%s) of (%s) to traverse the element", key, path)
+		}
+	}
+
+	return nil
+}
+
+// incValue adds the value to a number, a missing field is set to the value like in mongo.
+func incValue(document bson.M, path string, value interface{}) error {
+	current := lookupValue(document, path)
+	if current == nil {
+		return setValue(document, path, value)
+	}
+
+	switch number := current.(type) {
+	case int:
+		if increment, ok := value.(int); ok {
+			return setValue(document, path, number+increment)
+		}
+	case int64:
+		if increment, ok := value.(int); ok {
+			return setValue(document, path, number+int64(increment))
+		}
+	}
+
+	currentNumber, ok := toFloat(current)
+	increment, incrementOk := toFloat(value)
+	if !ok || !incrementOk {
+		return fmt.Errorf("cannot apply $inc to a value of non-numeric type at (%s)", path)
+	}
+
+	return setValue(document, path, currentNumber+increment)
+}
+
+// unsetValue removes the field, missing fields are left as they are like in mongo.
+func unsetValue(document bson.M, path string) {
+	keys := strings.Split(path, ".")
+	parent := document
+	if len(keys) > 1 {
+		var ok bool
+		if parent, ok = lookupValue(document, strings.Join(keys[:len(keys)-1], ".")).(bson.M); !ok {
+			return
+		}
+	}
+
+	delete(parent, keys[len(keys)-1])
+}
+
+func lookupValue(document bson.M, path string) interface{} {
+	values := lookupValues(document, strings.Split(path, "."))
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
+
+// lookupValues resolves a dotted path, descending into arrays of
+// sub-documents the same way mongo does for "menu.name" like keys.
+func lookupValues(document bson.M, keys []string) []interface{} {
+	value, ok := document[keys[0]]
+	if !ok {
+		return nil
+	}
+
+	if len(keys) == 1 {
+		return []interface{}{value}
+	}
+
+	switch v := value.(type) {
+	case bson.M:
+		return lookupValues(v, keys[1:])
+	case []interface{}:
+		if index, err := strconv.Atoi(keys[1]); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil
+			}
+			if len(keys) == 2 {
+				return []interface{}{v[index]}
+			}
+			if subDocument, ok := v[index].(bson.M); ok {
+				return lookupValues(subDocument, keys[2:])
+			}
+			return nil
+		}
+
+		var values []interface{}
+		for _, item := range v {
+			if subDocument, ok := item.(bson.M); ok {
+				values = append(values, lookupValues(subDocument, keys[1:])...)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func matchDocument(document bson.M, filter bson.M) bool {
+	for key, condition := range filter {
+		switch key {
+		case "$and":
+			for _, sub := range toDocuments(condition) {
+				if !matchDocument(document, sub) {
+					return false
+				}
+			}
+		case "$text":
+			// matched and scored by MemoryDataAccess.scoreText
+		case "$or":
+			matched := false
+			for _, sub := range toDocuments(condition) {
+				if matchDocument(document, sub) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return false
+			}
+		default:
+			values := lookupValues(document, strings.Split(key, "."))
+			if !matchField(values, condition) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func toDocuments(value interface{}) []bson.M {
+	items, _ := value.([]interface{})
+	documents := make([]bson.M, 0, len(items))
+	for _, item := range items {
+		if document, ok := item.(bson.M); ok {
+			documents = append(documents, document)
+		}
+	}
+
+	return documents
+}
+
+func matchField(values []interface{}, condition interface{}) bool {
+	if regex, ok := condition.(bson.RegEx); ok {
+		return matchRegex(values, regex)
+	}
+
+	operators, ok := condition.(bson.M)
+	if !ok || !hasOperators(operators) {
+		return anyValue(values, func(value interface{}) bool { return equalValues(value, condition) })
+	}
+
+	for operator, argument := range operators {
+		if !matchOperator(values, operator, argument) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchOperator(values []interface{}, operator string, argument interface{}) bool {
+	switch operator {
+	case "$eq":
+		return anyValue(values, func(value interface{}) bool { return equalValues(value, argument) })
+	case "$ne":
+		return !anyValue(values, func(value interface{}) bool { return equalValues(value, argument) })
+	case "$gt":
+		return compareAny(values, argument, func(result int) bool { return result > 0 })
+	case "$gte":
+		return compareAny(values, argument, func(result int) bool { return result >= 0 })
+	case "$lt":
+		return compareAny(values, argument, func(result int) bool { return result < 0 })
+	case "$lte":
+		return compareAny(values, argument, func(result int) bool { return result <= 0 })
+	case "$in":
+		items, _ := argument.([]interface{})
+		for _, item := range items {
+			if matchOperator(values, "$eq", item) {
+				return true
+			}
+		}
+		return false
+	case "$nin":
+		return !matchOperator(values, "$in", argument)
+	case "$exists":
+		return (len(values) > 0) == isTruthy(argument)
+	case "$regex":
+		regex, ok := argument.(bson.RegEx)
+		if !ok {
+			pattern, _ := argument.(string)
+			regex = bson.RegEx{Pattern: pattern}
+		}
+		return matchRegex(values, regex)
+	case "$geoWithin":
+		return matchCenterSphere(values, argument)
+	case "$nearSphere":
+		return matchNearSphere(values, argument)
+	}
+
+	return false
+}
+
+// matchRegex supports "i", "m" and "s" options, only strings are matched
+func matchRegex(values []interface{}, regex bson.RegEx) bool {
+	flags := ""
+	for _, option := range regex.Options {
+		if strings.ContainsRune("ims", option) {
+			flags += string(option)
+		}
+	}
+
+	pattern := regex.Pattern
+	if flags != "" {
+		pattern = fmt.Sprintf("(?%s)%s", flags, pattern)
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	return anyValue(values, func(value interface{}) bool {
+		text, ok := value.(string)
+		return ok && compiled.MatchString(text)
+	})
+}
+
+// anyValue reports whether predicate holds for one of the values
+// or for one of the elements when a value is an array.
+func anyValue(values []interface{}, predicate func(interface{}) bool) bool {
+	if len(values) == 0 {
+		return predicate(nil)
+	}
+
+	for _, value := range values {
+		if predicate(value) {
+			return true
+		}
+
+		if items, ok := value.([]interface{}); ok {
+			for _, item := range items {
+				if predicate(item) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func equalValues(a, b interface{}) bool {
+	if typeOrder(a) != typeOrder(b) {
+		return false
+	}
+
+	return compareValues(a, b) == 0
+}
+
+// compareAny matches range operators, which like in mongo
+// only compare values of the same type
+func compareAny(values []interface{}, argument interface{}, check func(int) bool) bool {
+	return anyValue(values, func(value interface{}) bool {
+		return typeOrder(value) == typeOrder(argument) && check(compareValues(value, argument))
+	})
+}
+
+// typeOrder follows the mongo BSON comparison order for the types we store.
+func typeOrder(value interface{}) int {
+	switch value.(type) {
+	case nil:
+		return 1
+	case int, int32, int64, float32, float64:
+		return 2
+	case string:
+		return 3
+	case bson.M:
+		return 4
+	case []interface{}:
+		return 5
+	case bson.ObjectId:
+		return 7
+	case bool:
+		return 8
+	case time.Time:
+		return 9
+	}
+
+	return 10
+}
+
+func compareValues(a, b interface{}) int {
+	orderA, orderB := typeOrder(a), typeOrder(b)
+	if orderA != orderB {
+		return compareInts(orderA, orderB)
+	}
+
+	switch orderA {
+	case 1:
+		return 0
+	case 2:
+		numberA, _ := toFloat(a)
+		numberB, _ := toFloat(b)
+		switch {
+		case numberA < numberB:
+			return -1
+		case numberA > numberB:
+			return 1
+		}
+		return 0
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	case 7:
+		return strings.Compare(string(a.(bson.ObjectId)), string(b.(bson.ObjectId)))
+	case 8:
+		boolA, boolB := a.(bool), b.(bool)
+		switch {
+		case boolA == boolB:
+			return 0
+		case boolB:
+			return -1
+		}
+		return 1
+	case 5:
+		itemsA, itemsB := a.([]interface{}), b.([]interface{})
+		for i := 0; i < len(itemsA) && i < len(itemsB); i++ {
+			if result := compareValues(itemsA[i], itemsB[i]); result != 0 {
+				return result
+			}
+		}
+		return compareInts(len(itemsA), len(itemsB))
+	case 9:
+		timeA, timeB := a.(time.Time), b.(time.Time)
+		switch {
+		case timeA.Before(timeB):
+			return -1
+		case timeA.After(timeB):
+			return 1
+		}
+		return 0
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,350 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ctx of operations in tests, none of them is cancelled
+var ctx = context.Background()
+
+type item struct {
+	ID    bson.ObjectId `bson:"_id,omitempty"`
+	Name  string        `bson:"name,omitempty"`
+	Score int           `bson:"score,omitempty"`
+	Tags  []string      `bson:"tags,omitempty"`
+}
+
+type MemoryDataAccessTestSuite struct {
+	suite.Suite
+
+	storage *MemoryDataAccess
+}
+
+func (suite *MemoryDataAccessTestSuite) SetupTest() {
+	suite.storage = NewMemoryDataAccess()
+	for _, i := range []item{
+		{ID: bson.NewObjectId(), Name: "first", Score: 3, Tags: []string{"a"}},
+		{ID: bson.NewObjectId(), Name: "second", Score: 1, Tags: []string{"a", "b"}},
+		{ID: bson.NewObjectId(), Name: "third", Score: 2},
+	} {
+		if err := suite.storage.Insert(ctx, i); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+}
+
+func (suite *MemoryDataAccessTestSuite) TestInsertGeneratesID() {
+	err := suite.storage.Insert(ctx, &item{Name: "fourth"})
+	suite.Assertions.Nil(err)
+
+	result := &item{}
+	err = suite.storage.Find(ctx, bson.M{"name": "fourth"}).One(result)
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(result.ID.Valid())
+}
+
+func (suite *MemoryDataAccessTestSuite) TestInsertDuplicateID() {
+	result := &item{}
+	suite.storage.Find(ctx, bson.M{"name": "first"}).One(result)
+
+	err := suite.storage.Insert(ctx, result)
+	suite.Assertions.True(mgo.IsDup(err))
+}
+
+func (suite *MemoryDataAccessTestSuite) TestFindByStructFilter() {
+	var result []item
+	err := suite.storage.Find(ctx, &item{Name: "second"}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 1)
+	suite.Assertions.Equal(result[0].Score, 1)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestFindArrayMembership() {
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"tags": "a"}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 2)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestFindOperators() {
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"score": bson.M{"$gte": 2}}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 2)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestFindRegex() {
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"name": bson.RegEx{Pattern: "^T", Options: "i"}}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 1)
+	suite.Assertions.Equal(result[0].Name, "third")
+
+	err = suite.storage.Find(ctx, bson.M{"name": bson.M{"$regex": bson.RegEx{Pattern: "^T"}}}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Empty(result)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestFindArrayIndex() {
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"tags.1": bson.M{"$exists": true}}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 1)
+	suite.Assertions.Equal(result[0].Name, "second")
+}
+
+func (suite *MemoryDataAccessTestSuite) TestTextSearch() {
+	err := suite.storage.Find(ctx, bson.M{"$text": bson.M{"$search": "first"}}).All(&[]item{})
+	suite.Assertions.Equal(err, errNoTextIndex)
+
+	err = suite.storage.EnsureIndex(mgo.Index{Key: []string{"$text:name", "$text:tags"}, Weights: map[string]int{"name": 10}})
+	suite.Assertions.Nil(err)
+
+	var result []struct {
+		Name  string  `bson:"name"`
+		Score float64 `bson:"score"`
+	}
+	err = suite.storage.Find(ctx, bson.M{"$and": []bson.M{{"$text": bson.M{"$search": "B First"}}, {"name": bson.M{"$ne": "third"}}}}).
+		Select(bson.M{"name": 1, "score": bson.M{"$meta": "textScore"}}).
+		Sort("$textScore:score").
+		All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 2)
+	suite.Assertions.Equal(result[0].Name, "first")
+	suite.Assertions.Equal(result[0].Score, 10.0)
+	suite.Assertions.Equal(result[1].Name, "second")
+	suite.Assertions.Equal(result[1].Score, 1.0)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestGeoQueries() {
+	suite.storage.DropCollection()
+	for name, coordinates := range map[string][]float64{
+		"kremlin":  {37.6175, 55.7520},
+		"arbat":    {37.5920, 55.7500},
+		"sheremet": {37.4146, 55.9726},
+	} {
+		point := bson.M{"type": "Point", "coordinates": coordinates}
+		if err := suite.storage.Insert(ctx, bson.M{"name": name, "location": point}); err != nil {
+			suite.T().Fatal(err.Error())
+		}
+	}
+	suite.storage.Insert(ctx, bson.M{"name": "nowhere"})
+
+	center := []float64{37.6180, 55.7525}
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"location": bson.M{"$nearSphere": bson.M{
+		"$geometry":    bson.M{"type": "Point", "coordinates": center},
+		"$maxDistance": 5000,
+	}}}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 2)
+	suite.Assertions.Equal(result[0].Name, "kremlin")
+	suite.Assertions.Equal(result[1].Name, "arbat")
+
+	count, err := suite.storage.Find(ctx, bson.M{"location": bson.M{"$geoWithin": bson.M{
+		"$centerSphere": []interface{}{center, 50000 / EarthRadius},
+	}}}).Count()
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(count, 3)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestSphereDistance() {
+	// a degree of latitude is about 111 km
+	distance := SphereDistance([]float64{0, 0}, []float64{0, 1})
+	suite.Assertions.InDelta(distance, 111319, 1)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestOneNotFound() {
+	err := suite.storage.Find(ctx, bson.M{"name": "missing"}).One(&item{})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestSortSkipLimit() {
+	var result []item
+	err := suite.storage.Find(ctx, nil).Sort("-score").Skip(1).Limit(1).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Len(result, 1)
+	suite.Assertions.Equal(result[0].Name, "third")
+}
+
+func (suite *MemoryDataAccessTestSuite) TestSortMultipleKeys() {
+	suite.storage.Insert(ctx, &item{Name: "first", Score: 5})
+
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"name": "first"}).Sort("name", "-score").All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result[0].Score, 5)
+	suite.Assertions.Equal(result[1].Score, 3)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestCount() {
+	count, err := suite.storage.Find(ctx, bson.M{"tags": "a"}).Count()
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(count, 2)
+
+	count, err = suite.storage.Find(ctx, nil).Skip(1).Count()
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(count, 2)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestEmptyResultIsNil() {
+	var result []item
+	err := suite.storage.Find(ctx, bson.M{"name": "missing"}).All(&result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Nil(result)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestSelect() {
+	result := &item{}
+	err := suite.storage.Find(ctx, bson.M{"name": "first"}).Select(bson.M{"tags": 1, "_id": 0}).One(result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(result, &item{Tags: []string{"a"}})
+
+	result = &item{}
+	err = suite.storage.Find(ctx, bson.M{"name": "first"}).Select(bson.M{"tags": 0}).One(result)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Nil(result.Tags)
+	suite.Assertions.Equal(result.Name, "first")
+	suite.Assertions.True(result.ID.Valid())
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateSetAndPush() {
+	err := suite.storage.Update(ctx, bson.M{"name": "third"}, bson.M{"$set": bson.M{"score": 10}})
+	suite.Assertions.Nil(err)
+
+	err = suite.storage.Update(ctx, bson.M{"name": "third"}, bson.M{"$push": bson.M{"tags": "c"}})
+	suite.Assertions.Nil(err)
+
+	result := &item{}
+	suite.storage.Find(ctx, bson.M{"name": "third"}).One(result)
+	suite.Assertions.Equal(result.Score, 10)
+	suite.Assertions.Equal(result.Tags, []string{"c"})
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateUnset() {
+	suite.storage.Insert(ctx, bson.M{"name": "nested", "score": 1, "inner": bson.M{"a": 1, "b": 2}})
+
+	err := suite.storage.Update(ctx, bson.M{"name": "nested"}, bson.M{"$unset": bson.M{"score": "", "inner.a": "", "missing": ""}})
+	suite.Assertions.Nil(err)
+
+	result := bson.M{}
+	suite.storage.Find(ctx, bson.M{"name": "nested"}).Select(bson.M{"_id": 0}).One(&result)
+	suite.Assertions.Equal(result, bson.M{"name": "nested", "inner": bson.M{"b": 2}})
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateInc() {
+	err := suite.storage.Update(ctx, bson.M{"name": "third"}, bson.M{"$inc": bson.M{"score": 5, "views": 1}})
+	suite.Assertions.Nil(err)
+
+	result := bson.M{}
+	suite.storage.Find(ctx, bson.M{"name": "third"}).One(&result)
+	suite.Assertions.Equal(result["score"], 7)
+	suite.Assertions.Equal(result["views"], 1)
+
+	err = suite.storage.Update(ctx, bson.M{"name": "third"}, bson.M{"$inc": bson.M{"name": 1}})
+	suite.Assertions.Error(err)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateAddToSet() {
+	for _, tag := range []string{"c", "a", "c"} {
+		err := suite.storage.Update(ctx, bson.M{"name": "first"}, bson.M{"$addToSet": bson.M{"tags": tag, "new": tag}})
+		suite.Assertions.Nil(err)
+	}
+
+	result := bson.M{}
+	suite.storage.Find(ctx, bson.M{"name": "first"}).One(&result)
+	suite.Assertions.Equal(result["tags"], []interface{}{"a", "c"})
+	suite.Assertions.Equal(result["new"], []interface{}{"c", "a"})
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdatePositionalAndPull() {
+	first, second := bson.NewObjectId(), bson.NewObjectId()
+	suite.storage.Insert(ctx, bson.M{"name": "nested", "items": []bson.M{{"_id": first, "n": 1}, {"_id": second, "n": 2}}})
+
+	err := suite.storage.Update(ctx, bson.M{"name": "nested", "items._id": second}, bson.M{"$set": bson.M{"items.$.n": 20}})
+	suite.Assertions.Nil(err)
+
+	err = suite.storage.Update(ctx, bson.M{"name": "nested"}, bson.M{"$pull": bson.M{"items": bson.M{"_id": first}}})
+	suite.Assertions.Nil(err)
+
+	result := bson.M{}
+	suite.storage.Find(ctx, bson.M{"name": "nested"}).One(&result)
+	suite.Assertions.Equal(result["items"], []interface{}{bson.M{"_id": second, "n": 20}})
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateReplace() {
+	original := &item{}
+	suite.storage.Find(ctx, bson.M{"name": "third"}).One(original)
+
+	err := suite.storage.Update(ctx, bson.M{"_id": original.ID}, &item{Name: "replaced"})
+	suite.Assertions.Nil(err)
+
+	result := &item{}
+	suite.storage.Find(ctx, bson.M{"_id": original.ID}).One(result)
+	suite.Assertions.Equal(result, &item{ID: original.ID, Name: "replaced"})
+}
+
+func (suite *MemoryDataAccessTestSuite) TestUpdateNotFound() {
+	err := suite.storage.Update(ctx, bson.M{"name": "missing"}, bson.M{"$set": bson.M{"score": 1}})
+
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestRemove() {
+	err := suite.storage.Remove(ctx, bson.M{"name": "first"})
+	suite.Assertions.Nil(err)
+
+	err = suite.storage.Remove(ctx, bson.M{"name": "first"})
+	suite.Assertions.Equal(err, mgo.ErrNotFound)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestRemoveAll() {
+	removed, err := suite.storage.RemoveAll(ctx, bson.M{"tags": "a"})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(removed, 2)
+
+	removed, err = suite.storage.RemoveAll(ctx, bson.M{"tags": "a"})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Zero(removed)
+
+	count, _ := suite.storage.Find(ctx, nil).Count()
+	suite.Assertions.Equal(count, 1)
+}
+
+func (suite *MemoryDataAccessTestSuite) TestCanceled() {
+	canceledContext, cancel := context.WithCancel(ctx)
+	cancel()
+
+	suite.Assertions.Equal(suite.storage.Insert(canceledContext, &item{Name: "fourth"}), context.Canceled)
+	suite.Assertions.Equal(suite.storage.Update(canceledContext, bson.M{"name": "first"}, bson.M{"$set": bson.M{"score": 1}}), context.Canceled)
+	suite.Assertions.Equal(suite.storage.Remove(canceledContext, bson.M{"name": "first"}), context.Canceled)
+	_, err := suite.storage.Find(canceledContext, nil).Count()
+	suite.Assertions.Equal(err, context.Canceled)
+
+	count, _ := suite.storage.Find(ctx, nil).Count()
+	suite.Assertions.Equal(count, 3)
+}
+
+func TestMemoryDataAccess(t *testing.T) {
+	suite.Run(t, new(MemoryDataAccessTestSuite))
+}
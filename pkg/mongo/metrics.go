@@ -0,0 +1,142 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"venues/pkg/metrics"
+
+	"gopkg.in/mgo.v2"
+)
+
+var (
+	_ DataAccessor = new(InstrumentedDataAccess)
+	_ Querier      = new(instrumentedQuery)
+)
+
+// operations of DataAccessor as they are labelled, queries are measured when they are run
+const (
+	operationFind      = "find"
+	operationCount     = "count"
+	operationInsert    = "insert"
+	operationUpdate    = "update"
+	operationRemove    = "remove"
+	operationRemoveAll = "remove_all"
+)
+
+// StorageMetrics count operations by collections and operations,
+// mgo.ErrNotFound is an answer rather than an error, so it isn't counted as one
+type StorageMetrics struct {
+	Operations *metrics.CounterVec
+	Errors     *metrics.CounterVec
+	Durations  *metrics.HistogramVec
+}
+
+func NewStorageMetrics(registry *metrics.Registry) *StorageMetrics {
+	labels := []string{"collection", "operation"}
+
+	return &StorageMetrics{
+		Operations: registry.NewCounterVec("storage_operations_total", "Storage operations.", labels...),
+		Errors:     registry.NewCounterVec("storage_operation_errors_total", "Failed storage operations.", labels...),
+		Durations:  registry.NewHistogramVec("storage_operation_duration_seconds", "Latency of storage operations.", metrics.DefaultBuckets, labels...),
+	}
+}
+
+func (storageMetrics *StorageMetrics) observe(collection string, operation string, start time.Time, err error) {
+	storageMetrics.Durations.Observe(time.Since(start).Seconds(), collection, operation)
+	storageMetrics.Operations.Inc(collection, operation)
+	if err != nil && err != mgo.ErrNotFound {
+		storageMetrics.Errors.Inc(collection, operation)
+	}
+}
+
+// InstrumentedDataAccess measures operations of the wrapped accessor, EnsureIndex isn't measured
+type InstrumentedDataAccess struct {
+	DataAccessor
+
+	Collection string
+	Metrics    *StorageMetrics
+}
+
+func (da *InstrumentedDataAccess) Find(ctx context.Context, query interface{}) Querier {
+	return &instrumentedQuery{Querier: da.DataAccessor.Find(ctx, query), access: da}
+}
+
+func (da *InstrumentedDataAccess) Insert(ctx context.Context, object interface{}) error {
+	start := time.Now()
+	err := da.DataAccessor.Insert(ctx, object)
+	da.Metrics.observe(da.Collection, operationInsert, start, err)
+
+	return err
+}
+
+func (da *InstrumentedDataAccess) Update(ctx context.Context, query interface{}, object interface{}) error {
+	start := time.Now()
+	err := da.DataAccessor.Update(ctx, query, object)
+	da.Metrics.observe(da.Collection, operationUpdate, start, err)
+
+	return err
+}
+
+func (da *InstrumentedDataAccess) Remove(ctx context.Context, query interface{}) error {
+	start := time.Now()
+	err := da.DataAccessor.Remove(ctx, query)
+	da.Metrics.observe(da.Collection, operationRemove, start, err)
+
+	return err
+}
+
+func (da *InstrumentedDataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	start := time.Now()
+	removed, err := da.DataAccessor.RemoveAll(ctx, query)
+	da.Metrics.observe(da.Collection, operationRemoveAll, start, err)
+
+	return removed, err
+}
+
+// instrumentedQuery keeps wrapping the query while it's built
+type instrumentedQuery struct {
+	Querier
+
+	access *InstrumentedDataAccess
+}
+
+func (q *instrumentedQuery) Select(fields interface{}) Querier {
+	return &instrumentedQuery{Querier: q.Querier.Select(fields), access: q.access}
+}
+
+func (q *instrumentedQuery) Sort(fields ...string) Querier {
+	return &instrumentedQuery{Querier: q.Querier.Sort(fields...), access: q.access}
+}
+
+func (q *instrumentedQuery) Skip(n int) Querier {
+	return &instrumentedQuery{Querier: q.Querier.Skip(n), access: q.access}
+}
+
+func (q *instrumentedQuery) Limit(n int) Querier {
+	return &instrumentedQuery{Querier: q.Querier.Limit(n), access: q.access}
+}
+
+func (q *instrumentedQuery) All(result interface{}) error {
+	start := time.Now()
+	err := q.Querier.All(result)
+	q.access.Metrics.observe(q.access.Collection, operationFind, start, err)
+
+	return err
+}
+
+func (q *instrumentedQuery) One(result interface{}) error {
+	start := time.Now()
+	err := q.Querier.One(result)
+	q.access.Metrics.observe(q.access.Collection, operationFind, start, err)
+
+	return err
+}
+
+func (q *instrumentedQuery) Count() (int, error) {
+	start := time.Now()
+	count, err := q.Querier.Count()
+	q.access.Metrics.observe(q.access.Collection, operationCount, start, err)
+
+	return count, err
+}
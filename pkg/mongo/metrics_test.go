@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"testing"
+
+	"venues/pkg/metrics"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type InstrumentedDataAccessTestSuite struct {
+	suite.Suite
+
+	metrics *StorageMetrics
+	storage *InstrumentedDataAccess
+}
+
+func (suite *InstrumentedDataAccessTestSuite) SetupTest() {
+	suite.metrics = NewStorageMetrics(metrics.NewRegistry())
+	suite.storage = &InstrumentedDataAccess{DataAccessor: NewMemoryDataAccess(), Collection: "items", Metrics: suite.metrics}
+}
+
+func (suite *InstrumentedDataAccessTestSuite) TestOperations() {
+	first := &item{ID: bson.NewObjectId(), Name: "first"}
+	suite.Assertions.Nil(suite.storage.Insert(ctx, first))
+	second := &item{ID: bson.NewObjectId(), Name: "second"}
+	suite.Assertions.Nil(suite.storage.Insert(ctx, second))
+	suite.Assertions.Nil(suite.storage.Update(ctx, bson.M{"_id": first.ID}, bson.M{"$set": bson.M{"score": 1}}))
+
+	result := []item{}
+	suite.Assertions.Nil(suite.storage.Find(ctx, bson.M{}).Sort("-name").Skip(1).Limit(1).All(&result))
+	suite.Assertions.Equal(result[0].Name, "first")
+	count, err := suite.storage.Find(ctx, bson.M{}).Count()
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(count, 2)
+	suite.Assertions.Equal(suite.storage.Find(ctx, bson.M{"name": "third"}).One(&item{}), mgo.ErrNotFound)
+
+	suite.Assertions.Nil(suite.storage.Remove(ctx, bson.M{"_id": first.ID}))
+	suite.Assertions.Error(suite.storage.Insert(ctx, &item{ID: second.ID}))
+	removed, err := suite.storage.RemoveAll(ctx, bson.M{})
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(removed, 1)
+
+	for operation, expected := range map[string]float64{
+		operationInsert:    3,
+		operationUpdate:    1,
+		operationFind:      2,
+		operationCount:     1,
+		operationRemove:    1,
+		operationRemoveAll: 1,
+	} {
+		suite.Assertions.Equal(suite.metrics.Operations.Value("items", operation), expected, operation)
+		suite.Assertions.Equal(suite.metrics.Durations.Count("items", operation), uint64(expected), operation)
+	}
+	suite.Assertions.Equal(suite.metrics.Errors.Value("items", operationInsert), float64(1))
+	suite.Assertions.Zero(suite.metrics.Errors.Value("items", operationFind))
+}
+
+func TestInstrumentedDataAccess(t *testing.T) {
+	suite.Run(t, new(InstrumentedDataAccessTestSuite))
+}
@@ -0,0 +1,125 @@
+package mongo
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// textScoreKey keeps the score of $text search in a copy of a matched document,
+// it never gets into results unless it's selected with {"$meta": "textScore"}
+const textScoreKey = "$textScore"
+
+var errNoTextIndex = errors.New("text index required for $text query")
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// TextWords finds positions of words in the text the way text search splits it
+func TextWords(text string) [][]int {
+	return wordPattern.FindAllStringIndex(text, -1)
+}
+
+// TextTerms splits the text into lower case words the way text search does,
+// unlike mongo it doesn't apply stemming and stop words
+func TextTerms(text string) []string {
+	words := TextWords(text)
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = strings.ToLower(text[word[0]:word[1]])
+	}
+
+	return terms
+}
+
+// textSearch finds $text condition of the filter, mongo allows it
+// on the top level of the filter or of its top level $and only
+func textSearch(filter bson.M) (string, bool) {
+	if text, ok := filter["$text"].(bson.M); ok {
+		search, _ := text["$search"].(string)
+		return search, true
+	}
+
+	for _, sub := range toDocuments(filter["$and"]) {
+		if search, ok := textSearch(sub); ok {
+			return search, true
+		}
+	}
+
+	return "", false
+}
+
+func isTextScoreMeta(value interface{}) bool {
+	meta, ok := value.(bson.M)
+	return ok && meta["$meta"] == "textScore"
+}
+
+// scoreText keeps documents that have any of the search terms in text index fields
+// and returns their copies with the score, each field adds its weight
+// multiplied by the share of its words matching the search
+func (da *MemoryDataAccess) scoreText(documents []bson.M, search string) ([]bson.M, error) {
+	da.mutex.RLock()
+	textFields := da.textFields
+	da.mutex.RUnlock()
+
+	if textFields == nil {
+		return nil, errNoTextIndex
+	}
+
+	terms := map[string]bool{}
+	for _, term := range TextTerms(search) {
+		terms[term] = true
+	}
+
+	var result []bson.M
+	for _, document := range documents {
+		score := 0.0
+		for field, weight := range textFields {
+			for _, value := range flattenValues(lookupValues(document, strings.Split(field, "."))) {
+				text, ok := value.(string)
+				if !ok {
+					continue
+				}
+
+				words := TextTerms(text)
+				matched := 0
+				for _, word := range words {
+					if terms[word] {
+						matched++
+					}
+				}
+				if matched > 0 {
+					score += float64(weight) * float64(matched) / float64(len(words))
+				}
+			}
+		}
+
+		if score == 0 {
+			continue
+		}
+
+		scored := bson.M{textScoreKey: score}
+		for key, value := range document {
+			scored[key] = value
+		}
+		result = append(result, scored)
+	}
+
+	return result, nil
+}
+
+// flattenValues puts items of arrays along with other values,
+// an array of strings is indexed item by item
+func flattenValues(values []interface{}) []interface{} {
+	var result []interface{}
+	for _, value := range values {
+		if items, ok := value.([]interface{}); ok {
+			result = append(result, items...)
+		} else {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	_ DataAccessor = new(TimeoutDataAccess)
+	_ Querier      = new(timeoutQuery)
+)
+
+var operations = []string{operationFind, operationCount, operationInsert, operationUpdate, operationRemove, operationRemoveAll}
+
+// Timeouts limit operations by their names, Default limits operations without their own timeout,
+// a zero timeout doesn't limit the operation
+type Timeouts struct {
+	Default    time.Duration
+	Operations map[string]time.Duration
+}
+
+func (timeouts Timeouts) For(operation string) time.Duration {
+	if timeout, ok := timeouts.Operations[operation]; ok {
+		return timeout
+	}
+
+	return timeouts.Default
+}
+
+// ParseTimeouts parses timeouts of operations like "find=2s; remove_all=1m"
+func ParseTimeouts(value string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+
+	for _, item := range strings.Split(value, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		operation := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !isOperation(operation) {
+			return nil, fmt.Errorf("storage timeout \"%s\" is not like find=2s, operations are %s", strings.TrimSpace(item), strings.Join(operations, ", "))
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("storage timeout of %s \"%s\" is not a duration like 2s", operation, strings.TrimSpace(parts[1]))
+		}
+		timeouts[operation] = timeout
+	}
+
+	return timeouts, nil
+}
+
+func isOperation(value string) bool {
+	for _, operation := range operations {
+		if value == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TimeoutDataAccess runs every operation with the context limited by the timeout of the operation,
+// an earlier deadline of the context is kept, queries are limited when they are run rather than built
+type TimeoutDataAccess struct {
+	DataAccessor
+
+	Timeouts Timeouts
+}
+
+func (da *TimeoutDataAccess) limit(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := da.Timeouts.For(operation)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+func (da *TimeoutDataAccess) Find(ctx context.Context, query interface{}) Querier {
+	return &timeoutQuery{ctx: ctx, query: query, access: da}
+}
+
+func (da *TimeoutDataAccess) Insert(ctx context.Context, object interface{}) error {
+	ctx, cancel := da.limit(ctx, operationInsert)
+	defer cancel()
+
+	return da.DataAccessor.Insert(ctx, object)
+}
+
+func (da *TimeoutDataAccess) Update(ctx context.Context, query interface{}, object interface{}) error {
+	ctx, cancel := da.limit(ctx, operationUpdate)
+	defer cancel()
+
+	return da.DataAccessor.Update(ctx, query, object)
+}
+
+func (da *TimeoutDataAccess) Remove(ctx context.Context, query interface{}) error {
+	ctx, cancel := da.limit(ctx, operationRemove)
+	defer cancel()
+
+	return da.DataAccessor.Remove(ctx, query)
+}
+
+func (da *TimeoutDataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	ctx, cancel := da.limit(ctx, operationRemoveAll)
+	defer cancel()
+
+	return da.DataAccessor.RemoveAll(ctx, query)
+}
+
+// timeoutQuery keeps how the query is built until it's run,
+// the query is found with the limited context then, which is cancelled once the query is done
+type timeoutQuery struct {
+	ctx    context.Context
+	query  interface{}
+	access *TimeoutDataAccess
+	steps  []func(Querier) Querier
+}
+
+func (q *timeoutQuery) then(step func(Querier) Querier) Querier {
+	steps := make([]func(Querier) Querier, len(q.steps), len(q.steps)+1)
+	copy(steps, q.steps)
+
+	return &timeoutQuery{ctx: q.ctx, query: q.query, access: q.access, steps: append(steps, step)}
+}
+
+func (q *timeoutQuery) build(ctx context.Context) Querier {
+	query := q.access.DataAccessor.Find(ctx, q.query)
+	for _, step := range q.steps {
+		query = step(query)
+	}
+
+	return query
+}
+
+func (q *timeoutQuery) Select(fields interface{}) Querier {
+	return q.then(func(query Querier) Querier { return query.Select(fields) })
+}
+
+func (q *timeoutQuery) Sort(fields ...string) Querier {
+	return q.then(func(query Querier) Querier { return query.Sort(fields...) })
+}
+
+func (q *timeoutQuery) Skip(n int) Querier {
+	return q.then(func(query Querier) Querier { return query.Skip(n) })
+}
+
+func (q *timeoutQuery) Limit(n int) Querier {
+	return q.then(func(query Querier) Querier { return query.Limit(n) })
+}
+
+func (q *timeoutQuery) All(result interface{}) error {
+	ctx, cancel := q.access.limit(q.ctx, operationFind)
+	defer cancel()
+
+	return q.build(ctx).All(result)
+}
+
+func (q *timeoutQuery) One(result interface{}) error {
+	ctx, cancel := q.access.limit(q.ctx, operationFind)
+	defer cancel()
+
+	return q.build(ctx).One(result)
+}
+
+func (q *timeoutQuery) Count() (int, error) {
+	ctx, cancel := q.access.limit(q.ctx, operationCount)
+	defer cancel()
+
+	return q.build(ctx).Count()
+}
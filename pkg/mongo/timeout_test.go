@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// deadlineDataAccess keeps the time left until the deadline of every operation, zero without a deadline
+type deadlineDataAccess struct {
+	*MemoryDataAccess
+
+	left []time.Duration
+}
+
+func (da *deadlineDataAccess) record(ctx context.Context) {
+	var left time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		left = time.Until(deadline)
+	}
+	da.left = append(da.left, left)
+}
+
+func (da *deadlineDataAccess) Find(ctx context.Context, query interface{}) Querier {
+	da.record(ctx)
+	return da.MemoryDataAccess.Find(ctx, query)
+}
+
+func (da *deadlineDataAccess) Insert(ctx context.Context, object interface{}) error {
+	da.record(ctx)
+	return da.MemoryDataAccess.Insert(ctx, object)
+}
+
+type TimeoutDataAccessTestSuite struct {
+	suite.Suite
+
+	recorder *deadlineDataAccess
+	storage  *TimeoutDataAccess
+}
+
+func (suite *TimeoutDataAccessTestSuite) SetupTest() {
+	suite.recorder = &deadlineDataAccess{MemoryDataAccess: NewMemoryDataAccess()}
+	suite.storage = &TimeoutDataAccess{
+		DataAccessor: suite.recorder,
+		Timeouts:     Timeouts{Default: time.Minute, Operations: map[string]time.Duration{operationInsert: time.Hour, operationCount: 0}},
+	}
+}
+
+func (suite *TimeoutDataAccessTestSuite) TestTimeouts() {
+	suite.Assertions.Nil(suite.storage.Insert(ctx, &item{Name: "first"}))
+	result := []item{}
+	suite.Assertions.Nil(suite.storage.Find(ctx, bson.M{"name": "first"}).Sort("name").Limit(1).All(&result))
+	count, err := suite.storage.Find(ctx, nil).Count()
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(count, 1)
+	suite.Assertions.Len(result, 1)
+	suite.Assertions.Len(suite.recorder.left, 3)
+	suite.Assertions.InDelta(suite.recorder.left[0].Seconds(), time.Hour.Seconds(), 1)
+	suite.Assertions.InDelta(suite.recorder.left[1].Seconds(), time.Minute.Seconds(), 1)
+	suite.Assertions.Zero(suite.recorder.left[2])
+}
+
+func (suite *TimeoutDataAccessTestSuite) TestEarlierDeadline() {
+	deadlineContext, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	suite.storage.Find(deadlineContext, nil).One(&item{})
+
+	suite.Assertions.True(suite.recorder.left[0] <= time.Second)
+}
+
+func (suite *TimeoutDataAccessTestSuite) TestCanceled() {
+	canceledContext, cancel := context.WithCancel(ctx)
+	cancel()
+
+	suite.Assertions.Equal(suite.storage.Insert(canceledContext, &item{Name: "first"}), context.Canceled)
+	suite.Assertions.Equal(suite.storage.Find(canceledContext, nil).One(&item{}), context.Canceled)
+	_, err := suite.storage.RemoveAll(canceledContext, nil)
+	suite.Assertions.Equal(err, context.Canceled)
+}
+
+func (suite *TimeoutDataAccessTestSuite) TestParseTimeouts() {
+	timeouts, err := ParseTimeouts(" find=2s; remove_all = 1m ;")
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(timeouts, map[string]time.Duration{operationFind: 2 * time.Second, operationRemoveAll: time.Minute})
+
+	for _, value := range []string{"find", "select=2s", "find=2", "find=-1s"} {
+		_, err := ParseTimeouts(value)
+
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func TestTimeoutDataAccess(t *testing.T) {
+	suite.Run(t, new(TimeoutDataAccessTestSuite))
+}
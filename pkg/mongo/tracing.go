@@ -0,0 +1,124 @@
+package mongo
+
+import (
+	"context"
+
+	"venues/pkg/tracing"
+
+	"gopkg.in/mgo.v2"
+)
+
+var (
+	_ DataAccessor = new(TracedDataAccess)
+	_ Querier      = new(tracedQuery)
+)
+
+// TracedDataAccess starts a span of every operation as a child of the span of its context,
+// queries are traced when they are run, EnsureIndex isn't traced
+type TracedDataAccess struct {
+	DataAccessor
+
+	Collection string
+}
+
+func (da *TracedDataAccess) start(ctx context.Context, operation string) (context.Context, *tracing.Span) {
+	ctx, span := tracing.Start(ctx, "mongo."+operation)
+	span.SetAttribute("db.system", "mongodb")
+	span.SetAttribute("db.collection", da.Collection)
+	span.SetAttribute("db.operation", operation)
+
+	return ctx, span
+}
+
+// end records errors other than mgo.ErrNotFound, which is an answer rather than an error
+func end(span *tracing.Span, err error) {
+	if err != mgo.ErrNotFound {
+		span.SetError(err)
+	}
+	span.End()
+}
+
+func (da *TracedDataAccess) Find(ctx context.Context, query interface{}) Querier {
+	return &tracedQuery{Querier: da.DataAccessor.Find(ctx, query), ctx: ctx, access: da}
+}
+
+func (da *TracedDataAccess) Insert(ctx context.Context, object interface{}) error {
+	ctx, span := da.start(ctx, operationInsert)
+	err := da.DataAccessor.Insert(ctx, object)
+	end(span, err)
+
+	return err
+}
+
+func (da *TracedDataAccess) Update(ctx context.Context, query interface{}, object interface{}) error {
+	ctx, span := da.start(ctx, operationUpdate)
+	err := da.DataAccessor.Update(ctx, query, object)
+	end(span, err)
+
+	return err
+}
+
+func (da *TracedDataAccess) Remove(ctx context.Context, query interface{}) error {
+	ctx, span := da.start(ctx, operationRemove)
+	err := da.DataAccessor.Remove(ctx, query)
+	end(span, err)
+
+	return err
+}
+
+func (da *TracedDataAccess) RemoveAll(ctx context.Context, query interface{}) (int, error) {
+	ctx, span := da.start(ctx, operationRemoveAll)
+	removed, err := da.DataAccessor.RemoveAll(ctx, query)
+	span.SetAttribute("db.removed", removed)
+	end(span, err)
+
+	return removed, err
+}
+
+// tracedQuery keeps the context of Find for the spans of running the query
+type tracedQuery struct {
+	Querier
+
+	ctx    context.Context
+	access *TracedDataAccess
+}
+
+func (q *tracedQuery) Select(fields interface{}) Querier {
+	return &tracedQuery{Querier: q.Querier.Select(fields), ctx: q.ctx, access: q.access}
+}
+
+func (q *tracedQuery) Sort(fields ...string) Querier {
+	return &tracedQuery{Querier: q.Querier.Sort(fields...), ctx: q.ctx, access: q.access}
+}
+
+func (q *tracedQuery) Skip(n int) Querier {
+	return &tracedQuery{Querier: q.Querier.Skip(n), ctx: q.ctx, access: q.access}
+}
+
+func (q *tracedQuery) Limit(n int) Querier {
+	return &tracedQuery{Querier: q.Querier.Limit(n), ctx: q.ctx, access: q.access}
+}
+
+func (q *tracedQuery) All(result interface{}) error {
+	_, span := q.access.start(q.ctx, operationFind)
+	err := q.Querier.All(result)
+	end(span, err)
+
+	return err
+}
+
+func (q *tracedQuery) One(result interface{}) error {
+	_, span := q.access.start(q.ctx, operationFind)
+	err := q.Querier.One(result)
+	end(span, err)
+
+	return err
+}
+
+func (q *tracedQuery) Count() (int, error) {
+	_, span := q.access.start(q.ctx, operationCount)
+	count, err := q.Querier.Count()
+	end(span, err)
+
+	return count, err
+}
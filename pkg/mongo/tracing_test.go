@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"venues/pkg/tracing"
+
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type recordingExporter struct {
+	spans []*tracing.SpanData
+}
+
+func (exporter *recordingExporter) Export(span *tracing.SpanData) error {
+	exporter.spans = append(exporter.spans, span)
+	return nil
+}
+
+type TracedDataAccessTestSuite struct {
+	suite.Suite
+
+	exporter *recordingExporter
+	previous *tracing.Tracer
+	storage  *TracedDataAccess
+}
+
+func (suite *TracedDataAccessTestSuite) SetupTest() {
+	suite.exporter = &recordingExporter{}
+	suite.previous = tracing.Default()
+	tracing.SetDefault(&tracing.Tracer{Exporter: suite.exporter})
+	suite.storage = &TracedDataAccess{DataAccessor: NewMemoryDataAccess(), Collection: "items"}
+}
+
+func (suite *TracedDataAccessTestSuite) TearDownTest() {
+	tracing.SetDefault(suite.previous)
+}
+
+func (suite *TracedDataAccessTestSuite) TestSpans() {
+	requestContext, request := tracing.Start(context.Background(), "request")
+	object := &item{ID: bson.NewObjectId(), Name: "first"}
+
+	suite.Assertions.Nil(suite.storage.Insert(requestContext, object))
+	find := suite.storage.Find(requestContext, bson.M{"name": "second"}).Sort("name").Limit(1)
+	suite.Assertions.Equal(find.One(&item{}), mgo.ErrNotFound)
+	suite.Assertions.Error(suite.storage.Insert(requestContext, object))
+	request.End()
+
+	spans := suite.exporter.spans
+	suite.Assertions.Len(spans, 4)
+	for i, operation := range []string{operationInsert, operationFind, operationInsert} {
+		suite.Assertions.Equal(spans[i].Name, "mongo."+operation)
+		suite.Assertions.Equal(spans[i].ParentID, request.Context().SpanID.String())
+		suite.Assertions.Equal(spans[i].Attributes["db.collection"], "items")
+	}
+	suite.Assertions.Empty(spans[1].Error)
+	suite.Assertions.NotEmpty(spans[2].Error)
+}
+
+func TestTracedDataAccess(t *testing.T) {
+	suite.Run(t, new(TracedDataAccessTestSuite))
+}
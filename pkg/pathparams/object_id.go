@@ -0,0 +1,54 @@
+package pathparams
+
+import (
+	"fmt"
+	"net/http"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const contextKeyPrefix = "pathparams."
+
+// ObjectIDs validates path params of the given names as hex ObjectIds
+// and stores parsed values on the context, see ObjectID.
+// Routes without such params are passed through, so it can be used by a whole group.
+func ObjectIDs(names ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			for _, name := range names {
+				if !hasParam(context, name) {
+					continue
+				}
+
+				value := context.Param(name)
+				if !bson.IsObjectIdHex(value) {
+					message := fmt.Sprintf("\"%s\" should be an ObjectId of 24 hex characters", name)
+					return httperrors.New(http.StatusBadRequest, message, httperrors.Detail{Field: name, Message: message})
+				}
+
+				context.Set(contextKeyPrefix+name, bson.ObjectIdHex(value))
+			}
+
+			return next(context)
+		}
+	}
+}
+
+// ObjectID returns the id parsed by ObjectIDs, empty id if there is no such param
+func ObjectID(context echo.Context, name string) bson.ObjectId {
+	id, _ := context.Get(contextKeyPrefix + name).(bson.ObjectId)
+	return id
+}
+
+func hasParam(context echo.Context, name string) bool {
+	for _, paramName := range context.ParamNames() {
+		if paramName == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,72 @@
+package pathparams
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type ObjectIDsTestSuite struct {
+	suite.Suite
+
+	echoContext echo.Context
+	middleware  echo.MiddlewareFunc
+}
+
+func (suite *ObjectIDsTestSuite) SetupTest() {
+	req := httptest.NewRequest(echo.GET, "/", nil)
+	suite.echoContext = echo.New().NewContext(req, httptest.NewRecorder())
+	suite.middleware = ObjectIDs("restaurant_id", "dish_id")
+}
+
+func (suite *ObjectIDsTestSuite) TestSuccess() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "5a8ad983591b381c73797522")
+
+	called := false
+	err := suite.middleware(func(context echo.Context) error {
+		called = true
+		suite.Assertions.Equal(ObjectID(context, "restaurant_id"), bson.ObjectIdHex("5a8ad983591b381c73797521"))
+		suite.Assertions.Equal(ObjectID(context, "dish_id"), bson.ObjectIdHex("5a8ad983591b381c73797522"))
+		return nil
+	})(suite.echoContext)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(called)
+}
+
+func (suite *ObjectIDsTestSuite) TestWithoutParams() {
+	called := false
+	err := suite.middleware(func(context echo.Context) error {
+		called = true
+		suite.Assertions.Equal(ObjectID(context, "restaurant_id"), bson.ObjectId(""))
+		return nil
+	})(suite.echoContext)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.True(called)
+}
+
+func (suite *ObjectIDsTestSuite) TestFail() {
+	suite.echoContext.SetParamNames("restaurant_id", "dish_id")
+	suite.echoContext.SetParamValues("5a8ad983591b381c73797521", "bad-object-id")
+
+	err := suite.middleware(func(context echo.Context) error {
+		suite.Assertions.Fail("handler must not be called")
+		return nil
+	})(suite.echoContext)
+
+	suite.Assertions.IsType(err, &httperrors.Error{})
+	suite.Assertions.Equal(err.(*httperrors.Error).Code, http.StatusBadRequest)
+	suite.Assertions.Equal(err.(*httperrors.Error).Details[0].Field, "dish_id")
+}
+
+func TestObjectIDs(t *testing.T) {
+	suite.Run(t, new(ObjectIDsTestSuite))
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// ClientIP tells clients apart by the address of the connection. X-Forwarded-For and X-Real-IP are read
+// only from trusted proxies, anyone else could send a new address in them with every request.
+// The client is the last address of X-Forwarded-For that isn't a trusted proxy.
+func ClientIP(trustedProxies []*net.IPNet) func(echo.Context) string {
+	return func(context echo.Context) string {
+		request := context.Request()
+		ip := request.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		if !isTrusted(trustedProxies, ip) {
+			return ip
+		}
+
+		if forwarded := request.Header.Get(echo.HeaderXForwardedFor); forwarded != "" {
+			addresses := strings.Split(forwarded, ",")
+			for i := len(addresses) - 1; i >= 0; i-- {
+				address := strings.TrimSpace(addresses[i])
+				if net.ParseIP(address) == nil {
+					break
+				}
+
+				ip = address
+				if !isTrusted(trustedProxies, ip) {
+					break
+				}
+			}
+
+			return ip
+		}
+
+		if realIP := strings.TrimSpace(request.Header.Get(echo.HeaderXRealIP)); net.ParseIP(realIP) != nil {
+			return realIP
+		}
+
+		return ip
+	}
+}
+
+func isTrusted(trustedProxies []*net.IPNet, address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseNetworks reads addresses and networks separated by "," like "10.0.0.1, 172.16.0.0/12"
+func ParseNetworks(value string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("address \"%s\" is not like 10.0.0.1 or 10.0.0.0/8", item)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("address \"%s\" is not like 10.0.0.1 or 10.0.0.0/8", item)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientIPTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ClientIPTestSuite) clientIP(trustedProxies string, remoteAddr string, headers map[string]string) string {
+	networks, err := ParseNetworks(trustedProxies)
+	suite.Assertions.Nil(err)
+
+	request := httptest.NewRequest(echo.GET, "/restaurants", nil)
+	request.RemoteAddr = remoteAddr
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	return ClientIP(networks)(echo.New().NewContext(request, httptest.NewRecorder()))
+}
+
+func (suite *ClientIPTestSuite) TestUntrustedHeaders() {
+	headers := map[string]string{echo.HeaderXForwardedFor: "1.1.1.1", echo.HeaderXRealIP: "2.2.2.2"}
+
+	suite.Assertions.Equal(suite.clientIP("", "10.0.0.1:4000", headers), "10.0.0.1")
+	suite.Assertions.Equal(suite.clientIP("10.0.0.2", "10.0.0.1:4000", headers), "10.0.0.1")
+}
+
+func (suite *ClientIPTestSuite) TestTrustedProxy() {
+	for forwarded, expected := range map[string]string{
+		"1.1.1.1":                       "1.1.1.1",
+		"9.9.9.9, 1.1.1.1":              "1.1.1.1",
+		"9.9.9.9, 1.1.1.1, 172.16.0.5":  "1.1.1.1",
+		"not an ip, 1.1.1.1":            "1.1.1.1",
+		"1.1.1.1, not an ip":            "10.0.0.1",
+		"172.16.0.6, 172.16.0.5":        "172.16.0.6",
+		"2001:db8::1":                   "2001:db8::1",
+		"9.9.9.9,1.1.1.1 , 172.16.0.99": "1.1.1.1",
+	} {
+		headers := map[string]string{echo.HeaderXForwardedFor: forwarded}
+
+		suite.Assertions.Equal(suite.clientIP("10.0.0.1, 172.16.0.0/12", "10.0.0.1:4000", headers), expected, forwarded)
+	}
+}
+
+func (suite *ClientIPTestSuite) TestTrustedProxyRealIP() {
+	headers := map[string]string{echo.HeaderXRealIP: "1.1.1.1"}
+
+	suite.Assertions.Equal(suite.clientIP("10.0.0.0/8", "10.0.0.1:4000", headers), "1.1.1.1")
+	suite.Assertions.Equal(suite.clientIP("10.0.0.0/8", "10.0.0.1:4000", nil), "10.0.0.1")
+}
+
+func (suite *ClientIPTestSuite) TestParseNetworksFail() {
+	for _, value := range []string{"10.0.0", "10.0.0.0/33", "localhost"} {
+		_, err := ParseNetworks(value)
+
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func TestClientIPTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientIPTestSuite))
+}
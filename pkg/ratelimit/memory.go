@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often full buckets are dropped, a full bucket is the same as a missing one
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// MemoryStore keeps buckets in the process, so every instance of the app limits clients on its own
+type MemoryStore struct {
+	// Now is replaced in tests
+	Now func() time.Time
+
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+func (store *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := store.Now()
+	store.sweep(now)
+
+	capacity := float64(limit.Requests)
+	interval := limit.interval()
+
+	current, ok := store.buckets[key]
+	if !ok {
+		current = &bucket{tokens: capacity, updated: now}
+		store.buckets[key] = current
+	}
+
+	if elapsed := now.Sub(current.updated); elapsed > 0 {
+		current.tokens += float64(elapsed) / float64(interval)
+		current.updated = now
+	}
+	if current.tokens > capacity {
+		current.tokens = capacity
+	}
+
+	result := Result{}
+	if current.tokens >= 1 {
+		current.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - current.tokens) * float64(interval))
+	}
+
+	result.Remaining = int(current.tokens)
+	result.Reset = time.Duration((capacity - current.tokens) * float64(interval))
+	current.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (store *MemoryStore) sweep(now time.Time) {
+	if store.swept.IsZero() {
+		store.swept = now
+	}
+	if now.Sub(store.swept) < sweepInterval {
+		return
+	}
+
+	for key, current := range store.buckets {
+		if !now.Before(current.full) {
+			delete(store.buckets, key)
+		}
+	}
+	store.swept = now
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{Now: time.Now, buckets: map[string]*bucket{}}
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type MemoryStoreTestSuite struct {
+	suite.Suite
+
+	now   time.Time
+	store *MemoryStore
+	limit Limit
+}
+
+func (suite *MemoryStoreTestSuite) SetupTest() {
+	suite.now = time.Date(2018, 2, 19, 10, 0, 0, 0, time.UTC)
+	suite.store = NewMemoryStore()
+	suite.store.Now = func() time.Time { return suite.now }
+	suite.limit = Limit{Requests: 3, Per: 3 * time.Second}
+}
+
+func (suite *MemoryStoreTestSuite) take(key string) Result {
+	result, err := suite.store.Take(key, suite.limit)
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+
+	return result
+}
+
+func (suite *MemoryStoreTestSuite) TestTake() {
+	for remaining := 2; remaining >= 0; remaining-- {
+		result := suite.take("client")
+		suite.Assertions.True(result.Allowed)
+		suite.Assertions.Equal(result.Remaining, remaining)
+	}
+
+	result := suite.take("client")
+	suite.Assertions.Equal(result, Result{Reset: 3 * time.Second, RetryAfter: time.Second})
+	suite.Assertions.True(suite.take("other").Allowed)
+
+	suite.now = suite.now.Add(1500 * time.Millisecond)
+	result = suite.take("client")
+	suite.Assertions.Equal(result, Result{Allowed: true, Reset: 2500 * time.Millisecond})
+	result = suite.take("client")
+	suite.Assertions.False(result.Allowed)
+	suite.Assertions.Equal(result.RetryAfter, 500*time.Millisecond)
+
+	suite.now = suite.now.Add(time.Hour)
+	result = suite.take("client")
+	suite.Assertions.Equal(result, Result{Allowed: true, Remaining: 2, Reset: time.Second})
+}
+
+func (suite *MemoryStoreTestSuite) TestSweep() {
+	suite.take("client")
+	suite.take("other")
+
+	suite.now = suite.now.Add(sweepInterval)
+	suite.take("client")
+
+	suite.Assertions.Len(suite.store.buckets, 1)
+	suite.Assertions.Contains(suite.store.buckets, "client")
+}
+
+func TestMemoryStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(MemoryStoreTestSuite))
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"venues/pkg/httperrors"
+	"venues/pkg/logging"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+const (
+	HeaderLimit     = "X-RateLimit-Limit"
+	HeaderRemaining = "X-RateLimit-Remaining"
+	HeaderReset     = "X-RateLimit-Reset"
+	HeaderRetry     = "Retry-After"
+)
+
+const errTooManyRequestsMsg = "Too many requests"
+
+type Config struct {
+	Skipper middleware.Skipper
+	Store   Store
+	// Default limits every client on routes without their own limits, all of them share one bucket
+	Default Limit
+	// Routes are limits keyed by methods and paths as they are registered, like "GET /restaurants/:restaurant_id",
+	// every route has its own bucket
+	Routes map[string]Limit
+	// Key tells clients apart, the address of the connection by default, see ClientIP
+	Key func(echo.Context) string
+}
+
+// Middleware takes a token from the bucket of the client for every request, requests finding it empty are
+// rejected with 429 and Retry-After, X-RateLimit-* headers tell clients how many requests they have left.
+// Requests are let through when the store fails, rate limiting isn't worth the outage.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+	if config.Key == nil {
+		config.Key = ClientIP(nil)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if config.Skipper(context) {
+				return next(context)
+			}
+
+			key := config.Key(context)
+			route := context.Request().Method + " " + context.Path()
+			limit, ok := config.Routes[route]
+			if ok {
+				key += " " + route
+			} else {
+				limit = config.Default
+			}
+			if limit.IsZero() {
+				return next(context)
+			}
+
+			result, err := config.Store.Take(key, limit)
+			if err != nil {
+				logging.From(context).WithError(err).Error("Error taking a rate limit token")
+				return next(context)
+			}
+
+			header := context.Response().Header()
+			header.Set(HeaderLimit, strconv.Itoa(limit.Requests))
+			header.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderReset, seconds(result.Reset))
+			if !result.Allowed {
+				header.Set(HeaderRetry, seconds(result.RetryAfter))
+				return httperrors.New(http.StatusTooManyRequests, errTooManyRequestsMsg)
+			}
+
+			return next(context)
+		}
+	}
+}
+
+// seconds rounds up, so clients waiting for them don't come back too early
+func seconds(duration time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(duration.Seconds())))
+}
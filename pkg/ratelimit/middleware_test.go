@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type MockStore struct {
+	mock.Mock
+}
+
+func (m *MockStore) Take(key string, limit Limit) (Result, error) {
+	args := m.Called(key, limit)
+	return args.Get(0).(Result), args.Error(1)
+}
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	store    *MockStore
+	config   Config
+	recorder *httptest.ResponseRecorder
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.store = &MockStore{}
+	suite.config = Config{
+		Store:   suite.store,
+		Default: Limit{Requests: 60, Per: time.Minute},
+		Routes:  map[string]Limit{"GET /restaurants": {Requests: 10, Per: time.Minute}},
+	}
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *MiddlewareTestSuite) serve(method string, path string) int {
+	request := httptest.NewRequest(method, path, nil)
+	request.RemoteAddr = "10.0.0.1:4000"
+	// the header isn't sent by a trusted proxy, so it's ignored
+	request.Header.Set(echo.HeaderXRealIP, "10.0.0.2")
+	context := echo.New().NewContext(request, suite.recorder)
+	context.SetPath(path)
+
+	handler := Middleware(suite.config)(func(context echo.Context) error {
+		return context.NoContent(http.StatusOK)
+	})
+	if err := handler(context); err != nil {
+		httperrors.Handler(err, context)
+	}
+
+	return context.Response().Status
+}
+
+func (suite *MiddlewareTestSuite) TestAllowed() {
+	suite.store.On("Take", "10.0.0.1", suite.config.Default).Return(Result{Allowed: true, Remaining: 59, Reset: 900 * time.Millisecond}, nil)
+
+	status := suite.serve(echo.POST, "/restaurants")
+
+	suite.store.AssertExpectations(suite.T())
+	suite.Assertions.Equal(status, http.StatusOK)
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderLimit), "60")
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderRemaining), "59")
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderReset), "1")
+	suite.Assertions.Empty(suite.recorder.Header().Get(HeaderRetry))
+}
+
+func (suite *MiddlewareTestSuite) TestRouteLimit() {
+	suite.config.Key = func(echo.Context) string { return "api_key:deploy" }
+	limit := suite.config.Routes["GET /restaurants"]
+	suite.store.On("Take", "api_key:deploy GET /restaurants", limit).Return(Result{Allowed: true, Remaining: 9}, nil)
+
+	status := suite.serve(echo.GET, "/restaurants")
+
+	suite.store.AssertExpectations(suite.T())
+	suite.Assertions.Equal(status, http.StatusOK)
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderLimit), "10")
+}
+
+func (suite *MiddlewareTestSuite) TestTooManyRequests() {
+	suite.store.On("Take", mock.Anything, mock.Anything).Return(Result{Reset: 6 * time.Second, RetryAfter: 1100 * time.Millisecond}, nil)
+
+	status := suite.serve(echo.GET, "/restaurants")
+
+	suite.Assertions.Equal(status, http.StatusTooManyRequests)
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderRemaining), "0")
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderReset), "6")
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderRetry), "2")
+}
+
+func (suite *MiddlewareTestSuite) TestUnlimited() {
+	suite.config.Default = Limit{}
+	suite.config.Skipper = func(context echo.Context) bool { return context.Path() == "/" }
+
+	suite.Assertions.Equal(suite.serve(echo.POST, "/restaurants"), http.StatusOK)
+	suite.Assertions.Equal(suite.serve(echo.GET, "/"), http.StatusOK)
+	suite.store.AssertNotCalled(suite.T(), "Take", mock.Anything, mock.Anything)
+}
+
+func (suite *MiddlewareTestSuite) TestStoreFail() {
+	suite.store.On("Take", mock.Anything, mock.Anything).Return(Result{}, errors.New("mocked error"))
+
+	status := suite.serve(echo.GET, "/restaurants")
+
+	suite.Assertions.Equal(status, http.StatusOK)
+	suite.Assertions.Empty(suite.recorder.Header().Get(HeaderLimit))
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
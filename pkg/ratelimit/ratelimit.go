@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit lets Requests requests in Per time, a bucket of Requests tokens is refilled evenly over Per,
+// the zero Limit doesn't limit anything
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (limit Limit) IsZero() bool {
+	return limit.Requests <= 0 || limit.Per <= 0
+}
+
+// interval is the time it takes to refill one token
+func (limit Limit) interval() time.Duration {
+	return limit.Per / time.Duration(limit.Requests)
+}
+
+// Result of taking a token, Reset is the time until the bucket is full again
+// and RetryAfter is the time until the next token when the request isn't allowed
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store keeps buckets by keys, MemoryStore keeps them in the process,
+// a shared store lets several instances of the app limit clients together
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+// ParseLimit reads limits like "100/m", "5/s" or "1000/24h", an empty string is the zero Limit
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Limit{}, nil
+	}
+
+	parts := strings.SplitN(value, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("rate limit \"%s\" is not like 100/m", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("rate limit \"%s\" has invalid number of requests", value)
+	}
+
+	unit := strings.TrimSpace(parts[1])
+	if unit == "s" || unit == "m" || unit == "h" {
+		unit = "1" + unit
+	}
+	per, err := time.ParseDuration(unit)
+	if err != nil || per <= 0 {
+		return Limit{}, fmt.Errorf("rate limit \"%s\" has invalid period", value)
+	}
+
+	return Limit{Requests: requests, Per: per}, nil
+}
+
+// ParseRouteLimits reads limits of routes separated by ";" like "GET /restaurants=20/m; POST /restaurants=5/m",
+// routes are methods and paths as they are registered
+func ParseRouteLimits(value string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+
+	for _, item := range strings.Split(value, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		route := strings.Fields(parts[0])
+		if len(parts) != 2 || len(route) != 2 {
+			return nil, fmt.Errorf("route rate limit \"%s\" is not like GET /restaurants=20/m", strings.TrimSpace(item))
+		}
+
+		limit, err := ParseLimit(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		limits[strings.ToUpper(route[0])+" "+route[1]] = limit
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ParseTestSuite struct {
+	suite.Suite
+}
+
+func (suite *ParseTestSuite) TestParseLimit() {
+	for value, expected := range map[string]Limit{
+		"":         {},
+		"100/m":    {Requests: 100, Per: time.Minute},
+		" 5 / s ":  {Requests: 5, Per: time.Second},
+		"1000/24h": {Requests: 1000, Per: 24 * time.Hour},
+		"0/h":      {Requests: 0, Per: time.Hour},
+		"10/500ms": {Requests: 10, Per: 500 * time.Millisecond},
+	} {
+		limit, err := ParseLimit(value)
+
+		suite.Assertions.Nil(err, value)
+		suite.Assertions.Equal(limit, expected, value)
+	}
+}
+
+func (suite *ParseTestSuite) TestParseLimitFail() {
+	for _, value := range []string{"100", "many/m", "-1/m", "100/week", "100/0s"} {
+		_, err := ParseLimit(value)
+
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func (suite *ParseTestSuite) TestParseRouteLimits() {
+	limits, err := ParseRouteLimits("get  /restaurants=20/m; POST /restaurants/:restaurant_id/dish=5/s;")
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(limits, map[string]Limit{
+		"GET /restaurants":                      {Requests: 20, Per: time.Minute},
+		"POST /restaurants/:restaurant_id/dish": {Requests: 5, Per: time.Second},
+	})
+
+	limits, err = ParseRouteLimits("")
+	suite.Assertions.Nil(err)
+	suite.Assertions.Empty(limits)
+}
+
+func (suite *ParseTestSuite) TestParseRouteLimitsFail() {
+	for _, value := range []string{"/restaurants=20/m", "GET /restaurants", "GET /restaurants=20"} {
+		_, err := ParseRouteLimits(value)
+
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func TestParseTestSuite(t *testing.T) {
+	suite.Run(t, new(ParseTestSuite))
+}
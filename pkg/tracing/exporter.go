@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterExporter writes every span as a json line, to stdout or to a file,
+// so traces can be looked at without a collector
+type WriterExporter struct {
+	mutex  sync.Mutex
+	output io.Writer
+}
+
+func NewWriterExporter(output io.Writer) *WriterExporter {
+	return &WriterExporter{output: output}
+}
+
+// NewFileExporter appends spans to the file
+func NewFileExporter(name string) (*WriterExporter, error) {
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriterExporter(file), nil
+}
+
+func (exporter *WriterExporter) Export(span *SpanData) error {
+	data, err := json.Marshal(span)
+	if err != nil {
+		return err
+	}
+
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	_, err = exporter.output.Write(append(data, '\n'))
+	return err
+}
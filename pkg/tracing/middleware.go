@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+type Config struct {
+	Skipper middleware.Skipper
+}
+
+// Middleware starts the span of every request as a child of the span in Traceparent header, when it's valid,
+// the request is served with the context of the span, so everything it calls can start child spans.
+// Traceparent of the span is sent back, so clients can find the trace of their requests.
+// The span records the status of the response and the error rendered by httperrors.Middleware inside it.
+func Middleware(config Config) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(context echo.Context) error {
+			if config.Skipper(context) {
+				return next(context)
+			}
+
+			request := context.Request()
+			ctx := request.Context()
+			if remote, err := ParseTraceparent(request.Header.Get(HeaderTraceparent)); err == nil {
+				ctx = WithRemote(ctx, remote)
+			}
+
+			ctx, span := Start(ctx, request.Method+" "+context.Path())
+			if span == nil {
+				return next(context)
+			}
+			defer span.End()
+
+			span.SetAttribute("http.method", request.Method)
+			span.SetAttribute("http.route", context.Path())
+			span.SetAttribute("http.target", request.RequestURI)
+			if id := request.Header.Get(echo.HeaderXRequestID); id != "" {
+				span.SetAttribute("request_id", id)
+			}
+			context.Response().Header().Set(HeaderTraceparent, FormatTraceparent(span.Context()))
+			context.SetRequest(request.WithContext(ctx))
+
+			err := next(context)
+			if err != nil {
+				span.SetError(err)
+			} else {
+				span.SetError(httperrors.ErrorOf(context))
+			}
+
+			span.SetAttribute("http.status_code", context.Response().Status)
+			return err
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+
+	exporter *RecordingExporter
+	previous *Tracer
+	recorder *httptest.ResponseRecorder
+}
+
+func (suite *MiddlewareTestSuite) SetupTest() {
+	suite.exporter = &RecordingExporter{}
+	suite.previous = Default()
+	SetDefault(&Tracer{Exporter: suite.exporter})
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *MiddlewareTestSuite) TearDownTest() {
+	SetDefault(suite.previous)
+}
+
+func (suite *MiddlewareTestSuite) serve(traceparent string, handler echo.HandlerFunc) {
+	request := httptest.NewRequest(echo.GET, "/restaurants/1", nil)
+	request.Header.Set(echo.HeaderXRequestID, "abc")
+	if traceparent != "" {
+		request.Header.Set(HeaderTraceparent, traceparent)
+	}
+	context := echo.New().NewContext(request, suite.recorder)
+	context.SetPath("/restaurants/:restaurant_id")
+
+	suite.Assertions.Nil(Middleware(Config{})(httperrors.Middleware()(handler))(context))
+}
+
+func (suite *MiddlewareTestSuite) TestSpan() {
+	var child *Span
+	suite.serve("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", func(context echo.Context) error {
+		_, child = Start(context.Request().Context(), "child")
+		child.End()
+		return context.NoContent(http.StatusOK)
+	})
+
+	suite.Assertions.Len(suite.exporter.spans, 2)
+	span := suite.exporter.spans[1]
+	suite.Assertions.Equal(span.Name, "GET /restaurants/:restaurant_id")
+	suite.Assertions.Equal(span.TraceID, "4bf92f3577b34da6a3ce929d0e0e4736")
+	suite.Assertions.Equal(span.ParentID, "00f067aa0ba902b7")
+	suite.Assertions.Equal(suite.exporter.spans[0].ParentID, span.SpanID)
+	suite.Assertions.Equal(span.Attributes["http.status_code"], http.StatusOK)
+	suite.Assertions.Equal(span.Attributes["request_id"], "abc")
+	suite.Assertions.Equal(suite.recorder.Header().Get(HeaderTraceparent), "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID+"-01")
+}
+
+func (suite *MiddlewareTestSuite) TestNewTrace() {
+	suite.serve("invalid", func(context echo.Context) error {
+		return errors.New("mocked error")
+	})
+
+	span := suite.exporter.spans[0]
+	suite.Assertions.NotEqual(span.TraceID, "")
+	suite.Assertions.Empty(span.ParentID)
+	suite.Assertions.Equal(span.Error, "mocked error")
+	suite.Assertions.Equal(span.Attributes["http.status_code"], http.StatusInternalServerError)
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
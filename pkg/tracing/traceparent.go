@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// HeaderTraceparent is the W3C Trace Context header, see https://www.w3.org/TR/trace-context/
+const HeaderTraceparent = "Traceparent"
+
+const (
+	traceparentVersion = "00"
+	flagSampled        = 0x01
+)
+
+var errTraceparent = errors.New("traceparent is not like 00-<trace id>-<parent id>-<flags>")
+
+// ParseTraceparent reads the span context of the caller, headers of later versions
+// are read by the fields of version 00 as the spec says
+func ParseTraceparent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == traceparentVersion && len(parts) != 4) {
+		return SpanContext{}, errTraceparent
+	}
+
+	// fields are decoded right into the span context
+	spanContext := SpanContext{}
+	version, flags := []byte{0}, []byte{0}
+	for i, field := range [][]byte{version, spanContext.TraceID[:], spanContext.SpanID[:], flags} {
+		if len(parts[i]) != 2*len(field) || strings.ToLower(parts[i]) != parts[i] {
+			return SpanContext{}, errTraceparent
+		}
+		if _, err := hex.Decode(field, []byte(parts[i])); err != nil {
+			return SpanContext{}, errTraceparent
+		}
+	}
+
+	spanContext.Sampled = flags[0]&flagSampled != 0
+	if !spanContext.IsValid() {
+		return SpanContext{}, errTraceparent
+	}
+
+	return spanContext, nil
+}
+
+// FormatTraceparent writes the span context for the services called
+func FormatTraceparent(spanContext SpanContext) string {
+	flags := 0
+	if spanContext.Sampled {
+		flags |= flagSampled
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%02x", traceparentVersion, spanContext.TraceID, spanContext.SpanID, flags)
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type TraceparentTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TraceparentTestSuite) TestParse() {
+	spanContext, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(spanContext.TraceID.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	suite.Assertions.Equal(spanContext.SpanID.String(), "00f067aa0ba902b7")
+	suite.Assertions.True(spanContext.Sampled)
+	suite.Assertions.Equal(FormatTraceparent(spanContext), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	spanContext, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	suite.Assertions.Nil(err)
+	suite.Assertions.False(spanContext.Sampled)
+	suite.Assertions.Equal(FormatTraceparent(spanContext), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+}
+
+func (suite *TraceparentTestSuite) TestParseFail() {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bx-01",
+	} {
+		_, err := ParseTraceparent(value)
+
+		suite.Assertions.Error(err, value)
+	}
+}
+
+func TestTraceparentTestSuite(t *testing.T) {
+	suite.Run(t, new(TraceparentTestSuite))
+}
@@ -0,0 +1,222 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+type SpanID [8]byte
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is what is propagated to other services, see Traceparent
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Sampled spans are exported, spans of a trace that isn't sampled are only propagated
+	Sampled bool
+}
+
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceID.IsValid() && spanContext.SpanID.IsValid()
+}
+
+// Exporter sends ended spans somewhere, it's called by the goroutine ending the span
+type Exporter interface {
+	Export(*SpanData) error
+}
+
+// Tracer makes spans of the service, a tracer without an exporter makes no spans at all
+type Tracer struct {
+	Service  string
+	Exporter Exporter
+	// OnError is told about spans failed to be exported
+	OnError func(error)
+}
+
+var defaultTracer = &Tracer{}
+
+// Default is the tracer of the app, it's configured by assembly
+func Default() *Tracer {
+	return defaultTracer
+}
+
+// SetDefault replaces the tracer of the app, it has to be called before the app starts serving
+func SetDefault(tracer *Tracer) {
+	defaultTracer = tracer
+}
+
+// SpanData is an ended span as exporters get it
+type SpanData struct {
+	Service    string                 `json:"service,omitempty"`
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	DurationMS float64                `json:"duration_ms"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Span is a timed operation of a trace, methods of a nil span do nothing,
+// so code doesn't check if tracing is configured
+type Span struct {
+	tracer   *Tracer
+	name     string
+	context  SpanContext
+	parentID SpanID
+	start    time.Time
+
+	mutex      sync.Mutex
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+}
+
+type spanContextKey struct{}
+
+type remoteContextKey struct{}
+
+// Start makes a child of the span of the context, or of the remote span of the context, see WithRemote,
+// or the root of a new trace, by the tracer of the parent span or the default tracer
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	tracer := Default()
+	parent := SpanContext{Sampled: true}
+	if span := FromContext(ctx); span != nil {
+		tracer, parent = span.tracer, span.context
+	} else if remote, ok := ctx.Value(remoteContextKey{}).(SpanContext); ok {
+		parent = remote
+	}
+
+	return tracer.start(ctx, name, parent)
+}
+
+func (tracer *Tracer) start(ctx context.Context, name string, parent SpanContext) (context.Context, *Span) {
+	if tracer.Exporter == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		tracer:     tracer,
+		name:       name,
+		context:    SpanContext{TraceID: parent.TraceID, SpanID: newSpanID(), Sampled: parent.Sampled},
+		parentID:   parent.SpanID,
+		start:      time.Now(),
+		attributes: map[string]interface{}{},
+	}
+	if !span.context.TraceID.IsValid() {
+		rand.Read(span.context.TraceID[:])
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// WithRemote makes the span of another service the parent of spans started with the context
+func WithRemote(ctx context.Context, remote SpanContext) context.Context {
+	return context.WithValue(ctx, remoteContextKey{}, remote)
+}
+
+// FromContext is the span started last with the context, nil without it
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+func (span *Span) Context() SpanContext {
+	if span == nil {
+		return SpanContext{}
+	}
+
+	return span.context
+}
+
+func (span *Span) SetAttribute(key string, value interface{}) {
+	if span == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	span.attributes[key] = value
+}
+
+// SetError marks the span failed, nil error doesn't change it
+func (span *Span) SetError(err error) {
+	if span == nil || err == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+
+	span.err = err.Error()
+}
+
+// End exports the span once, when it's sampled
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	if span.ended || !span.context.Sampled {
+		span.ended = true
+		span.mutex.Unlock()
+		return
+	}
+	span.ended = true
+
+	end := time.Now()
+	attributes := make(map[string]interface{}, len(span.attributes))
+	for key, value := range span.attributes {
+		attributes[key] = value
+	}
+	data := &SpanData{
+		Service:    span.tracer.Service,
+		Name:       span.name,
+		TraceID:    span.context.TraceID.String(),
+		SpanID:     span.context.SpanID.String(),
+		Start:      span.start,
+		End:        end,
+		DurationMS: float64(end.Sub(span.start)) / float64(time.Millisecond),
+		Attributes: attributes,
+		Error:      span.err,
+	}
+	if span.parentID.IsValid() {
+		data.ParentID = span.parentID.String()
+	}
+	span.mutex.Unlock()
+
+	if err := span.tracer.Exporter.Export(data); err != nil && span.tracer.OnError != nil {
+		span.tracer.OnError(err)
+	}
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	rand.Read(id[:])
+
+	return id
+}
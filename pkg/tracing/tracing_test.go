@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// RecordingExporter keeps exported spans in the order they ended
+type RecordingExporter struct {
+	mutex sync.Mutex
+	spans []*SpanData
+	err   error
+}
+
+func (exporter *RecordingExporter) Export(span *SpanData) error {
+	exporter.mutex.Lock()
+	defer exporter.mutex.Unlock()
+
+	exporter.spans = append(exporter.spans, span)
+	return exporter.err
+}
+
+type TracerTestSuite struct {
+	suite.Suite
+
+	exporter *RecordingExporter
+	previous *Tracer
+}
+
+func (suite *TracerTestSuite) SetupTest() {
+	suite.exporter = &RecordingExporter{}
+	suite.previous = Default()
+	SetDefault(&Tracer{Service: "test", Exporter: suite.exporter})
+}
+
+func (suite *TracerTestSuite) TearDownTest() {
+	SetDefault(suite.previous)
+}
+
+func (suite *TracerTestSuite) TestSpans() {
+	ctx, root := Start(context.Background(), "root")
+	root.SetAttribute("http.method", "GET")
+	childContext, child := Start(ctx, "child")
+	child.SetError(errors.New("mocked error"))
+	child.End()
+	child.End()
+	root.End()
+
+	suite.Assertions.Equal(FromContext(childContext), child)
+	suite.Assertions.Len(suite.exporter.spans, 2)
+	childData, rootData := suite.exporter.spans[0], suite.exporter.spans[1]
+	suite.Assertions.Equal(childData.Name, "child")
+	suite.Assertions.Equal(childData.TraceID, rootData.TraceID)
+	suite.Assertions.Equal(childData.ParentID, rootData.SpanID)
+	suite.Assertions.Equal(childData.Error, "mocked error")
+	suite.Assertions.Empty(rootData.ParentID)
+	suite.Assertions.Equal(rootData.Service, "test")
+	suite.Assertions.Equal(rootData.Attributes, map[string]interface{}{"http.method": "GET"})
+	suite.Assertions.False(rootData.End.Before(rootData.Start))
+}
+
+func (suite *TracerTestSuite) TestRemote() {
+	remote := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}, Sampled: true}
+
+	_, span := Start(WithRemote(context.Background(), remote), "server")
+	span.End()
+
+	suite.Assertions.Equal(span.Context().TraceID, remote.TraceID)
+	suite.Assertions.Equal(suite.exporter.spans[0].ParentID, remote.SpanID.String())
+}
+
+func (suite *TracerTestSuite) TestNotSampled() {
+	remote := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{2}}
+
+	ctx, span := Start(WithRemote(context.Background(), remote), "server")
+	_, child := Start(ctx, "child")
+	child.End()
+	span.End()
+
+	suite.Assertions.False(child.Context().Sampled)
+	suite.Assertions.Equal(child.Context().TraceID, remote.TraceID)
+	suite.Assertions.Empty(suite.exporter.spans)
+}
+
+func (suite *TracerTestSuite) TestWithoutExporter() {
+	SetDefault(&Tracer{})
+
+	ctx, span := Start(context.Background(), "root")
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("mocked error"))
+	span.End()
+
+	suite.Assertions.Nil(span)
+	suite.Assertions.Nil(FromContext(ctx))
+	suite.Assertions.Equal(span.Context(), SpanContext{})
+}
+
+func (suite *TracerTestSuite) TestExportFail() {
+	var exported error
+	suite.exporter.err = errors.New("mocked error")
+	SetDefault(&Tracer{Exporter: suite.exporter, OnError: func(err error) { exported = err }})
+
+	_, span := Start(context.Background(), "root")
+	span.End()
+
+	suite.Assertions.Equal(exported, suite.exporter.err)
+}
+
+func (suite *TracerTestSuite) TestWriterExporter() {
+	output := &bytes.Buffer{}
+	SetDefault(&Tracer{Exporter: NewWriterExporter(output)})
+
+	_, span := Start(context.Background(), "root")
+	span.End()
+
+	data := &SpanData{}
+	suite.Assertions.Nil(json.Unmarshal(output.Bytes(), data))
+	suite.Assertions.Equal(data.Name, "root")
+	suite.Assertions.Equal(data.SpanID, span.Context().SpanID.String())
+}
+
+func TestTracerTestSuite(t *testing.T) {
+	suite.Run(t, new(TracerTestSuite))
+}
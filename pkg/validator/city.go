@@ -0,0 +1,236 @@
+package validator
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CityResolver knows which cities exist,
+// so we don't have cities like "Moscow" and "Mascow"
+type CityResolver interface {
+	// Resolve returns the canonical name of the city, typos are forgiven
+	Resolve(name string) (string, bool)
+	// Suggest returns up to limit known cities similar to the name
+	Suggest(name string, limit int) []string
+}
+
+type city struct {
+	name       string
+	population int
+}
+
+// FileCityResolver is backed by a GeoNames-style csv, it needs "name" column,
+// "asciiname", "alternatenames" and "population" columns are used if present
+type FileCityResolver struct {
+	// names are normalized names, ascii names and alternate names of cities
+	names map[string]*city
+}
+
+var errNoNameColumn = errors.New("cities csv has no \"name\" column")
+
+func NewFileCityResolver(path string) (*FileCityResolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadCityResolver(file)
+}
+
+func ReadCityResolver(reader io.Reader) (*FileCityResolver, error) {
+	rows := csv.NewReader(reader)
+	header, err := rows.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errNoNameColumn
+	}
+
+	resolver := &FileCityResolver{names: map[string]*city{}}
+	for line := 2; ; line++ {
+		row, err := rows.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		item := &city{name: value("name")}
+		if population := value("population"); population != "" {
+			if item.population, err = strconv.Atoi(population); err != nil {
+				return nil, fmt.Errorf("cities csv line %d: population %q is not a number", line, population)
+			}
+		}
+
+		resolver.add(item.name, item)
+		resolver.add(value("asciiname"), item)
+		for _, alternate := range strings.Split(value("alternatenames"), ",") {
+			resolver.add(alternate, item)
+		}
+	}
+
+	return resolver, nil
+}
+
+// add keeps the most populated city for names shared by several cities
+func (resolver *FileCityResolver) add(name string, item *city) {
+	key := normalizeCity(name)
+	if key == "" {
+		return
+	}
+
+	if known, ok := resolver.names[key]; ok && known.population >= item.population {
+		return
+	}
+	resolver.names[key] = item
+}
+
+func (resolver *FileCityResolver) Resolve(name string) (string, bool) {
+	key := normalizeCity(name)
+	if item, ok := resolver.names[key]; ok {
+		return item.name, true
+	}
+
+	matches := resolver.closest(key)
+	if len(matches) == 0 || matches[0].distance > maxTypos(key) {
+		return "", false
+	}
+
+	return matches[0].city.name, true
+}
+
+func (resolver *FileCityResolver) Suggest(name string, limit int) []string {
+	key := normalizeCity(name)
+	// a third of the name may be wrong, more than that is not similar anymore
+	maxDistance := len([]rune(key))/3 + 1
+
+	suggestions := []string{}
+	for _, match := range resolver.closest(key) {
+		if len(suggestions) == limit || match.distance > maxDistance {
+			break
+		}
+		suggestions = append(suggestions, match.city.name)
+	}
+
+	return suggestions
+}
+
+type cityMatch struct {
+	city     *city
+	distance int
+}
+
+// closest lists every city once by its closest name,
+// the closest go first and the most populated go first among equally close
+func (resolver *FileCityResolver) closest(key string) []cityMatch {
+	best := map[*city]int{}
+	for name, item := range resolver.names {
+		distance := editDistance(key, name)
+		if known, ok := best[item]; !ok || distance < known {
+			best[item] = distance
+		}
+	}
+
+	matches := make([]cityMatch, 0, len(best))
+	for item, distance := range best {
+		matches = append(matches, cityMatch{city: item, distance: distance})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		if matches[i].city.population != matches[j].city.population {
+			return matches[i].city.population > matches[j].city.population
+		}
+		return matches[i].city.name < matches[j].city.name
+	})
+
+	return matches
+}
+
+// maxTypos forgives one typo in short names and two in longer ones,
+// very short names have to match exactly
+func maxTypos(key string) int {
+	switch length := len([]rune(key)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	}
+
+	return 2
+}
+
+// normalizeCity makes "  saint-petersburg " and "Saint Petersburg" the same
+func normalizeCity(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, " ")
+}
+
+// editDistance is Levenshtein distance counted in runes,
+// swapped neighbour letters count as a single typo
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	// beforePrevious, previous and current are the last rows of the distance matrix
+	beforePrevious := make([]int, len(target)+1)
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				current[j] = minInt(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+
+	return previous[len(target)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
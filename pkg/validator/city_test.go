@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CityResolverTestSuite struct {
+	suite.Suite
+
+	resolver *FileCityResolver
+}
+
+func (suite *CityResolverTestSuite) SetupTest() {
+	resolver, err := NewFileCityResolver("testdata/cities.csv")
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	suite.resolver = resolver
+}
+
+func (suite *CityResolverTestSuite) TestResolve() {
+	for name, expected := range map[string]string{
+		"Moscow":           "Moscow",
+		"  moscow ":        "Moscow",
+		"Москва":           "Moscow",
+		"saint-petersburg": "Saint Petersburg",
+		"St. Petersburg":   "Saint Petersburg",
+		"Leningrad":        "Saint Petersburg",
+		"Mascow":           "Moscow",
+		"Sant Petersburgh": "Saint Petersburg",
+		"paris":            "Paris",
+		"Pari":             "Paris",
+		"Prauge":           "Prague",
+	} {
+		result, ok := suite.resolver.Resolve(name)
+
+		suite.Assertions.True(ok, name)
+		suite.Assertions.Equal(result, expected, name)
+	}
+}
+
+func (suite *CityResolverTestSuite) TestResolveUnknown() {
+	for _, name := range []string{"", "Gotham", "Mscw", "Pr"} {
+		_, ok := suite.resolver.Resolve(name)
+
+		suite.Assertions.False(ok, name)
+	}
+}
+
+func (suite *CityResolverTestSuite) TestSuggest() {
+	suite.Assertions.Equal(suite.resolver.Suggest("Mscw", 3), []string{"Moscow"})
+	suite.Assertions.Equal(suite.resolver.Suggest("Prgaue", 3), []string{"Prague"})
+	suite.Assertions.Equal(suite.resolver.Suggest("Gotham", 3), []string{})
+}
+
+func (suite *CityResolverTestSuite) TestReadErrors() {
+	_, err := ReadCityResolver(strings.NewReader("city,population\nMoscow,1\n"))
+	suite.Assertions.Equal(err, errNoNameColumn)
+
+	_, err = ReadCityResolver(strings.NewReader("name,population\nMoscow,many\n"))
+	suite.Assertions.Error(err)
+
+	_, err = NewFileCityResolver("testdata/missing.csv")
+	suite.Assertions.Error(err)
+}
+
+func TestCityResolver(t *testing.T) {
+	suite.Run(t, new(CityResolverTestSuite))
+}
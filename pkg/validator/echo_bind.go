@@ -1,33 +1,127 @@
 package validator
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"venues/pkg/httperrors"
+
 	"github.com/labstack/echo"
 	"gopkg.in/go-playground/validator.v9"
 )
 
-// function for synchronize models.Restaurant.City
-// we don't have to have cities like "Moscow" and "Mascow"
-func ValidateCity(fl validator.FieldLevel) bool {
-	// fake implementation
-	// actually we have to have a separate service as Redis
-	// that has a data set of cities
-	if fl.Field().String() == "Mascow" {
+// citySuggestions is how many known cities are suggested for an unknown one
+const citySuggestions = 3
+
+// ValidateCity builds "city" validation, cities with typos are valid,
+// Validator.Validate replaces them with canonical names
+func ValidateCity(cities CityResolver) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		_, ok := cities.Resolve(fl.Field().String())
+		return ok
+	}
+}
+
+// ValidateLngLat checks GeoJSON coordinates, which are [longitude, latitude]
+func ValidateLngLat(fl validator.FieldLevel) bool {
+	coordinates, ok := fl.Field().Interface().([]float64)
+	if !ok || len(coordinates) != 2 {
 		return false
 	}
 
-	return true
+	longitude, latitude := coordinates[0], coordinates[1]
+	return longitude >= -180 && longitude <= 180 && latitude >= -90 && latitude <= 90
 }
 
 type Validator struct {
 	validator *validator.Validate
+	cities    CityResolver
 }
 
+// Validate replaces cities of fields with "city" tag by their canonical names,
+// unknown cities are reported with suggestions
 func (v *Validator) Validate(i interface{}) error {
-	return v.validator.Struct(i)
+	if err := v.validator.Struct(i); err != nil {
+		return v.suggestCities(err)
+	}
+
+	v.canonicalizeCities(reflect.ValueOf(i))
+	return nil
+}
+
+func (v *Validator) suggestCities(err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	result := httperrors.BadRequest(validationErrors)
+	for i, fieldError := range validationErrors {
+		if fieldError.Tag() == "city" {
+			result.Details[i].Suggestions = v.cities.Suggest(fmt.Sprint(fieldError.Value()), citySuggestions)
+		}
+	}
+
+	return result
+}
+
+func (v *Validator) canonicalizeCities(value reflect.Value) {
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		tags := strings.Split(value.Type().Field(i).Tag.Get("validate"), ",")
+		if !hasTag(tags, "city") {
+			continue
+		}
+
+		field := value.Field(i)
+		switch {
+		case field.Kind() == reflect.String && field.CanSet():
+			v.canonicalizeCity(field)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			for j := 0; j < field.Len(); j++ {
+				v.canonicalizeCity(field.Index(j))
+			}
+		}
+	}
+}
+
+func (v *Validator) canonicalizeCity(field reflect.Value) {
+	if name, ok := v.cities.Resolve(field.String()); ok {
+		field.SetString(name)
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, item := range tags {
+		if item == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// json names are reported in errors, since that's what clients send
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
 }
 
-func NewValidator() echo.Validator {
+func NewValidator(cities CityResolver) echo.Validator {
 	validatorType := validator.New()
-	validatorType.RegisterValidation("city", ValidateCity)
-	return &Validator{validator: validatorType}
+	validatorType.RegisterTagNameFunc(jsonFieldName)
+	validatorType.RegisterValidation("city", ValidateCity(cities))
+	validatorType.RegisterValidation("lnglat", ValidateLngLat)
+	return &Validator{validator: validatorType, cities: cities}
 }
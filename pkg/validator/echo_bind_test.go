@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"venues/pkg/httperrors"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/suite"
+)
+
+type place struct {
+	City        string    `json:"city" validate:"required,city"`
+	Cities      []string  `json:"cities" validate:"omitempty,dive,city"`
+	Coordinates []float64 `json:"coordinates" validate:"omitempty,lnglat"`
+}
+
+type ValidatorTestSuite struct {
+	suite.Suite
+
+	validator echo.Validator
+}
+
+func (suite *ValidatorTestSuite) SetupTest() {
+	cities, err := ReadCityResolver(strings.NewReader("name,alternatenames\nMoscow,Moskva\nParis,\n"))
+	if err != nil {
+		suite.T().Fatal(err.Error())
+	}
+	suite.validator = NewValidator(cities)
+}
+
+func (suite *ValidatorTestSuite) TestCanonicalizesCities() {
+	object := &place{City: "moskva", Cities: []string{"Mascow", "PARIS"}}
+
+	err := suite.validator.Validate(object)
+
+	suite.Assertions.Nil(err)
+	suite.Assertions.Equal(object, &place{City: "Moscow", Cities: []string{"Moscow", "Paris"}})
+}
+
+func (suite *ValidatorTestSuite) TestUnknownCitySuggestions() {
+	err := suite.validator.Validate(&place{City: "Mscw"})
+
+	httpError, ok := err.(*httperrors.Error)
+	suite.Assertions.True(ok)
+	suite.Assertions.Equal(httpError.Code, http.StatusBadRequest)
+	suite.Assertions.Equal(httpError.Details, []httperrors.Detail{
+		{Field: "city", Message: "is not a known city", Suggestions: []string{"Moscow"}},
+	})
+}
+
+func (suite *ValidatorTestSuite) TestLngLat() {
+	suite.Assertions.Nil(suite.validator.Validate(&place{City: "Paris", Coordinates: []float64{2.35, 48.85}}))
+	suite.Assertions.Error(suite.validator.Validate(&place{City: "Paris", Coordinates: []float64{48.85, 200}}))
+	suite.Assertions.Error(suite.validator.Validate(&place{City: "Paris", Coordinates: []float64{2.35}}))
+}
+
+func TestValidator(t *testing.T) {
+	suite.Run(t, new(ValidatorTestSuite))
+}